@@ -0,0 +1,285 @@
+package goptional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// Accessor is implemented by pointers to every optional of this package, so that generic
+// code such as loggers, validators and patchers can read and write optionals without
+// knowing their concrete types.
+type Accessor interface {
+	Optional
+	// Interface returns value-flag pair of the optional, with nil value if it is invalid.
+	Interface() (interface{}, bool)
+	// Reset makes the optional invalid.
+	Reset()
+	// SetAny sets the optional valid holding v, or invalid if v is nil.
+	SetAny(v interface{}) error
+	// Kind describes the value held by the optional.
+	Kind() Kind
+}
+
+// Kind describes the value held by an optional.
+type Kind int
+
+const (
+	// KindOther is any value not described by the kinds below, e.g. a struct.
+	KindOther Kind = iota
+	KindBool
+	KindInt
+	KindUint
+	KindFloat
+	KindString
+	KindBytes
+	KindTime
+	KindDate
+	KindDuration
+	KindDecimal
+	KindBigInt
+	KindUUID
+	KindAddr
+	KindPrefix
+	KindURL
+	KindJSON
+	KindEnum
+	KindSlice
+	KindMap
+)
+
+var kindNames = map[Kind]string{
+	KindOther:    "other",
+	KindBool:     "bool",
+	KindInt:      "int",
+	KindUint:     "uint",
+	KindFloat:    "float",
+	KindString:   "string",
+	KindBytes:    "bytes",
+	KindTime:     "time",
+	KindDate:     "date",
+	KindDuration: "duration",
+	KindDecimal:  "decimal",
+	KindBigInt:   "bigint",
+	KindUUID:     "uuid",
+	KindAddr:     "addr",
+	KindPrefix:   "prefix",
+	KindURL:      "url",
+	KindJSON:     "json",
+	KindEnum:     "enum",
+	KindSlice:    "slice",
+	KindMap:      "map",
+}
+
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// KindOf returns Kind of v, which may be an optional or a plain value.
+func KindOf(v interface{}) Kind {
+	if k, ok := v.(interface{ Kind() Kind }); ok {
+		return k.Kind()
+	}
+	return kindOf(reflect.TypeOf(v))
+}
+
+// kindOf returns Kind of values of type t.
+func kindOf(t reflect.Type) Kind {
+	if t == nil {
+		return KindOther
+	}
+	switch v := reflect.New(t).Interface().(type) {
+	case interface{ Kind() Kind }:
+		return v.Kind()
+	case *time.Time:
+		return KindTime
+	case *time.Duration:
+		return KindDuration
+	case *Dec:
+		return KindDecimal
+	case **big.Int:
+		return KindBigInt
+	case *UUIDValue:
+		return KindUUID
+	case *netip.Addr:
+		return KindAddr
+	case *netip.Prefix:
+		return KindPrefix
+	case **url.URL:
+		return KindURL
+	case *json.RawMessage:
+		return KindJSON
+	}
+	switch k := t.Kind(); {
+	case k == reflect.Bool:
+		return KindBool
+	case isInt(k):
+		return KindInt
+	case isUint(k):
+		return KindUint
+	case isNumber(k):
+		return KindFloat
+	case k == reflect.String:
+		return KindString
+	case k == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return KindBytes
+	case k == reflect.Slice || k == reflect.Array:
+		return KindSlice
+	case k == reflect.Map:
+		return KindMap
+	}
+	return KindOther
+}
+
+// Interface returns value-flag pair of this optional, with nil value if it is invalid.
+func (o Of[T]) Interface() (interface{}, bool) {
+	if v, ok := o.Get(); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+// Reset makes this optional invalid.
+func (o *Of[T]) Reset() {
+	*o = Of[T]{}
+}
+
+// SetAny sets this optional valid holding v, or invalid if v is nil or an invalid optional.
+// Number is converted into numeric T as long as it fits exactly, and other values are
+// converted into T only if they are of the same kind, e.g. string into a named string type.
+// If v can not be converted, optional value is invalid and error is returned.
+func (o *Of[T]) SetAny(v interface{}) error {
+	if i, ok := v.(interface{ Interface() (interface{}, bool) }); ok {
+		v, _ = i.Interface()
+	}
+	if v == nil {
+		o.Reset()
+		return nil
+	}
+	if val, ok := v.(T); ok {
+		o.val, o.set = val, true
+		return nil
+	}
+	if err := convertValue(v, reflect.ValueOf(&o.val).Elem()); err != nil {
+		o.set = false
+		return fmt.Errorf("Unable to set %T into %T: %w", v, o.val, err)
+	}
+	o.set = true
+	return nil
+}
+
+// convertValue sets v into rv following the rules of Of.SetAny. Numbers are converted only
+// when rv holds them exactly.
+func convertValue(v interface{}, rv reflect.Value) error {
+	src := reflect.ValueOf(v)
+	sk, dk := src.Kind(), rv.Kind()
+	switch {
+	case isNumber(sk) && isNumber(dk):
+		switch {
+		case isInt(sk):
+			return setInt(rv, src.Int())
+		case isUint(sk):
+			u := src.Uint()
+			switch {
+			case isUint(dk):
+				return setUint(rv, u)
+			case u <= math.MaxInt64:
+				return setInt(rv, int64(u))
+			case isInt(dk):
+				return errOverflow
+			}
+			// Beyond int64, float only holds u which it represents exactly.
+			f := float64(u)
+			if f >= math.MaxUint64 || uint64(f) != u || (dk == reflect.Float32 && float64(float32(f)) != f) {
+				return errOverflow
+			}
+			rv.SetFloat(f)
+			return nil
+		default:
+			return setFloat(rv, src.Float())
+		}
+	case sk == dk && src.Type().ConvertibleTo(rv.Type()):
+		rv.Set(src.Convert(rv.Type()))
+		return nil
+	}
+	return errKind
+}
+
+// Kind describes value held by this optional.
+func (o Of[T]) Kind() Kind {
+	return kindOf(reflect.TypeOf(&o.val).Elem())
+}
+
+// Interface returns value-flag pair of this nullable, with nil value if it is not present.
+func (n Nullable[T]) Interface() (interface{}, bool) {
+	return n.opt.Interface()
+}
+
+// Reset makes this nullable absent.
+func (n *Nullable[T]) Reset() {
+	*n = Nullable[T]{}
+}
+
+// SetAny sets this nullable present holding v, or null if v is nil, following the rules
+// of Of.SetAny.
+func (n *Nullable[T]) SetAny(v interface{}) error {
+	if v == nil {
+		n.SetNull()
+		return nil
+	}
+	n.null = false
+	return n.opt.SetAny(v)
+}
+
+// Kind describes value held by this nullable.
+func (n Nullable[T]) Kind() Kind {
+	return n.opt.Kind()
+}
+
+// Interface returns copy of value-flag pair of this optional, with nil value if it is invalid.
+func (b BytesAs[E]) Interface() (interface{}, bool) {
+	if v, ok := b.Get(); ok {
+		return v, true
+	}
+	return nil, false
+}
+
+// SetAny sets copy of v into this optional following the rules of Of.SetAny.
+func (b *BytesAs[E]) SetAny(v interface{}) error {
+	err := b.opt.SetAny(v)
+	b.val = cloneBytes(b.val)
+	return err
+}
+
+// Kind describes value held by this optional.
+func (d Date) Kind() Kind {
+	return KindDate
+}
+
+// Reset makes this optional invalid.
+func (e *Enum[T, S]) Reset() {
+	*e = Enum[T, S]{}
+}
+
+// SetAny sets this optional following the rules of Of.SetAny. Value which is not allowed
+// makes it invalid and error is returned.
+func (e *Enum[T, S]) SetAny(v interface{}) error {
+	err := e.opt.SetAny(v)
+	if e.check() {
+		return fmt.Errorf("Unable to set %v into %T: %w", e.val, e.val, errEnum)
+	}
+	return err
+}
+
+// Kind describes value held by this optional.
+func (e Enum[T, S]) Kind() Kind {
+	return KindEnum
+}
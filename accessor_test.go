@@ -0,0 +1,181 @@
+package optional_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestAccessor(t *testing.T) {
+	// Scenario: Every optional is accessed without knowing its type
+	// Given: Pointers to optionals of each kind
+	// When: Kind is described
+	// Then: Kind matches the value held by the optional
+	testCase := []struct {
+		opt  optional.Accessor
+		kind optional.Kind
+	}{
+		{&optional.Int{}, optional.KindInt},
+		{&optional.Uint8{}, optional.KindUint},
+		{&optional.Float32{}, optional.KindFloat},
+		{&optional.String{}, optional.KindString},
+		{&optional.Bool{}, optional.KindBool},
+		{&optional.Bytes{}, optional.KindBytes},
+		{&optional.Time{}, optional.KindTime},
+		{&optional.Date{}, optional.KindDate},
+		{&optional.Duration{}, optional.KindDuration},
+		{&optional.Decimal{}, optional.KindDecimal},
+		{&optional.BigInt{}, optional.KindBigInt},
+		{&optional.UUID{}, optional.KindUUID},
+		{&optional.Addr{}, optional.KindAddr},
+		{&optional.Prefix{}, optional.KindPrefix},
+		{&optional.URL{}, optional.KindURL},
+		{&optional.RawJSON{}, optional.KindJSON},
+		{&optional.Enum[status, statuses]{}, optional.KindEnum},
+		{&optional.SliceOf[optional.Int]{}, optional.KindSlice},
+		{&optional.MapOf[string, int]{}, optional.KindMap},
+		{&optional.Nullable[string]{}, optional.KindString},
+		{&optional.Of[struct{}]{}, optional.KindOther},
+	}
+
+	for _, v := range testCase {
+		if k := v.opt.Kind(); k != v.kind {
+			t.Errorf("[optional] Unexpected kind of %T, expected: %v, got: %v", v.opt, v.kind, k)
+		}
+	}
+
+	// Scenario: Values are set and read through Accessor
+	// Given: Value which is, or converts into, the type of optional
+	// When: Set through SetAny
+	// Then: Optional is valid and Interface returns the value
+	u, _ := url.Parse("https://example.com")
+	setCase := []struct {
+		opt      optional.Accessor
+		v        interface{}
+		expected interface{}
+	}{
+		{&optional.Int{}, 5, 5},
+		{&optional.Int8{}, int64(-5), int8(-5)},
+		{&optional.Float64{}, 2, 2.0},
+		{&optional.Uint{}, 2.0, uint(2)},
+		{&optional.String{}, "s", "s"},
+		{&optional.Bool{}, true, true},
+		{&optional.Time{}, time.Unix(0, 0), time.Unix(0, 0)},
+		{&optional.URL{}, u, u},
+		{&optional.Int64{}, optional.NewInt(7, true), int64(7)},
+		{&optional.Int64{}, uint64(1<<53 + 1), int64(1<<53 + 1)},
+		{&optional.Float32{}, int64(1 << 24), float32(1 << 24)},
+		{&optional.Float64{}, uint64(1 << 63), float64(1 << 63)},
+		{&optional.Enum[status, statuses]{}, "active", status("active")},
+	}
+
+	for _, v := range setCase {
+		if err := v.opt.SetAny(v.v); err != nil {
+			t.Errorf("[optional] Fail to set %v into %T, error: %v", v.v, v.opt, err)
+		}
+		got, ok := v.opt.Interface()
+		if !ok || !v.opt.Ok() || got != v.expected {
+			t.Errorf("[optional] Unexpected value of %T, expected: %v, got: %v (%v)", v.opt, v.expected, got, ok)
+		}
+	}
+
+	// Scenario: Values which do not convert are rejected
+	// Given: Value of incompatible type, overflowing or not allowed
+	// When: Set through SetAny
+	// Then: Returns error and optional is invalid
+	errCase := []struct {
+		opt optional.Accessor
+		v   interface{}
+	}{
+		{&optional.Int{}, "5"},
+		{&optional.Int8{}, 300},
+		{&optional.Int{}, 1.5},
+		{&optional.Uint{}, -1},
+		{&optional.String{}, 5},
+		{&optional.Bool{}, 1},
+		{&optional.Int64{}, uint64(1 << 63)},
+		{&optional.Float32{}, int64(16777217)},
+		{&optional.Float64{}, int64(1<<53 + 1)},
+		{&optional.Float64{}, uint64(1<<63 + 1)},
+		{&optional.Enum[status, statuses]{}, "deleted"},
+	}
+
+	for _, v := range errCase {
+		if err := v.opt.SetAny(v.v); err == nil {
+			t.Errorf("[optional] Expected error from setting %v into %T", v.v, v.opt)
+		}
+		if v.opt.Ok() {
+			t.Errorf("[optional] Expected %T to be invalid after setting %v", v.opt, v.v)
+		}
+	}
+
+	// Scenario: Optional is reset
+	// Given: Valid optionals
+	// When: Reset or set nil
+	// Then: Optional is invalid and Interface returns nil
+	i := optional.NewInt(5, true)
+	i.Reset()
+	if v, ok := i.Interface(); ok || v != nil {
+		t.Errorf("[optional] Unexpected value after Reset: %v (%v)", v, ok)
+	}
+	s := optional.NewString("s", true)
+	if err := s.SetAny(nil); err != nil || s.Ok() {
+		t.Errorf("[optional] Unexpected result of setting nil: %v, %v", s, err)
+	}
+
+	// Scenario: Nullable is set null and reset
+	// Given: Present nullable
+	// When: Set nil, then reset
+	// Then: Nullable is null, then absent
+	n := optional.NewNullable(5, true)
+	if err := n.SetAny(nil); err != nil || !n.IsNull() {
+		t.Errorf("[optional] Expected null nullable, got: %+v, %v", n, err)
+	}
+	n.Reset()
+	if !n.IsAbsent() {
+		t.Errorf("[optional] Expected absent nullable, got: %+v", n)
+	}
+
+	// Scenario: Enum forgets unknown value when reset
+	// Given: Enum set with a value which is not allowed
+	// When: Reset
+	// Then: Enum is no longer unknown
+	e := optional.Enum[status, statuses]{}
+	if err := e.SetAny(status("deleted")); !e.IsUnknown() || err == nil {
+		t.Errorf("[optional] Expected unknown enum, got: %+v, %v", e, err)
+	}
+	e.Reset()
+	if e.IsUnknown() {
+		t.Errorf("[optional] Unexpected unknown enum after Reset")
+	}
+
+	// Scenario: Bytes does not share memory through Accessor
+	// Given: Slice set through SetAny
+	// When: Slice and value returned by Interface are modified
+	// Then: Optional is unchanged
+	src := []byte{1, 2}
+	b := optional.Bytes{}
+	b.SetAny(src)
+	src[0] = 9
+	got, _ := b.Interface()
+	got.([]byte)[1] = 9
+	if v, _ := b.Get(); v[0] != 1 || v[1] != 2 {
+		t.Errorf("[optional] Unexpected aliasing, got: %v", v)
+	}
+
+	// Scenario: Kind of plain values is described
+	// Given: Plain values and optionals
+	// When: Described by KindOf
+	// Then: Kind matches the value
+	if k := optional.KindOf(int16(1)); k != optional.KindInt {
+		t.Errorf("[optional] Unexpected kind of int16: %v", k)
+	}
+	if k := optional.KindOf(optional.NewDate(time.Now(), true)); k != optional.KindDate {
+		t.Errorf("[optional] Unexpected kind of Date: %v", k)
+	}
+	if k := optional.KindOf(nil); k != optional.KindOther || k.String() != "other" {
+		t.Errorf("[optional] Unexpected kind of nil: %v", k)
+	}
+}
@@ -0,0 +1,155 @@
+package goptional
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// BigInt is optional form of *big.Int.
+// It unmarshals JSON integer and quoted integer of any size, and marshals into JSON number.
+// It is stored in BSON as int64 when it fits, otherwise as decimal128, and in SQL as int64
+// when it fits, otherwise as text.
+type BigInt = Of[*big.Int]
+
+// NewBigInt creates a new optional
+func NewBigInt(val *big.Int, ok bool) BigInt {
+	return BigInt{val, ok}
+}
+
+// NumberFormat tells BigIntAs how to marshal into JSON.
+type NumberFormat interface {
+	// Quoted reports whether number is marshaled into JSON string.
+	Quoted() bool
+}
+
+// Number marshals into JSON number, as BigInt does.
+type Number struct{}
+
+// Quoted implements NumberFormat
+func (Number) Quoted() bool {
+	return false
+}
+
+// Quoted marshals into JSON string, for clients such as JavaScript which lose precision
+// beyond 53 bits.
+type Quoted struct{}
+
+// Quoted implements NumberFormat
+func (Quoted) Quoted() bool {
+	return true
+}
+
+// BigIntAs is optional form of *big.Int which marshals into JSON using zero value of F,
+// e.g. BigIntAs[Quoted] for JSON string. Otherwise it behaves as BigInt.
+//
+// BigIntAs wraps Of[*big.Int] rather than aliasing it since it marshals with its own format.
+type BigIntAs[F NumberFormat] struct {
+	Of[*big.Int]
+}
+
+// NewBigIntAs creates a new optional
+func NewBigIntAs[F NumberFormat](val *big.Int, ok bool) BigIntAs[F] {
+	return BigIntAs[F]{Of[*big.Int]{val, ok}}
+}
+
+// MarshalJSON marshals optional into JSON number or string depending on F.
+// Invalid optional is marshaled as null.
+func (o BigIntAs[F]) MarshalJSON() ([]byte, error) {
+	var f F
+	if v, ok := o.Get(); ok {
+		return marshalBigInt(v, f.Quoted())
+	}
+	return []byte("null"), nil
+}
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// parseBigInt parses decimal integer s.
+func parseBigInt(s string) (*big.Int, error) {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("Unable to parse integer %q", s)
+	}
+	return i, nil
+}
+
+// unmarshalBigInt unmarshals JSON integer or quoted integer b into i.
+func unmarshalBigInt(b []byte, i **big.Int) error {
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	v, err := parseBigInt(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+
+// marshalBigInt marshals i into JSON number, or string if quoted.
+func marshalBigInt(i *big.Int, quoted bool) ([]byte, error) {
+	if i == nil {
+		return []byte("null"), nil
+	}
+	if quoted {
+		return json.Marshal(i.String())
+	}
+	return []byte(i.String()), nil
+}
+
+// bigIntBSON returns value of i stored in BSON.
+func bigIntBSON(i *big.Int) (interface{}, error) {
+	if i == nil {
+		return nil, nil
+	}
+	if i.IsInt64() {
+		return i.Int64(), nil
+	}
+	return decimal128Of(i, 0)
+}
+
+// decodeBSONBigInt decodes integral BSON number, decimal128 or string into rv of big.Int.
+func decodeBSONBigInt(kind byte, data []byte, rv reflect.Value) error {
+	s, err := bsonNumberText(kind, data)
+	if err == nil {
+		var d Dec
+		if d, err = ParseDec(s); err == nil {
+			if r := d.Rat(); !r.IsInt() {
+				err = errFraction
+			} else {
+				rv.Set(reflect.ValueOf(r.Num()).Elem())
+			}
+		}
+	}
+	if err != nil {
+		return bsonError(kind, rv.Type(), err)
+	}
+	return nil
+}
+
+// scanBigInt converts SQL integer or text into i.
+func scanBigInt(src interface{}, i **big.Int) error {
+	var s string
+	switch v := src.(type) {
+	case int64:
+		*i = big.NewInt(v)
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("Unable to scan %T into big.Int", src)
+	}
+	v, err := parseBigInt(s)
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
@@ -0,0 +1,125 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestBigInt(t *testing.T) {
+	type ts struct {
+		A optional.BigInt `json:"a" bson:"a"`
+	}
+	type qs struct {
+		A optional.BigIntAs[optional.Quoted] `json:"a" bson:"a"`
+	}
+
+	// Scenario: Unmarshal big integer from JSON then marshal again
+	// Given: JSON message with integer beyond int64 or quoted integer
+	testCase := []struct {
+		message []byte
+		ok      bool
+		number  string
+		quoted  string
+	}{
+		// When: Integer is valid
+		// Then: It is marshaled back exactly as number, or string by BigIntAs[Quoted]
+		{[]byte(`{"a":12345678901234567890}`), true, `{"a":12345678901234567890}`, `{"a":"12345678901234567890"}`},
+		{[]byte(`{"a":"-12345678901234567890"}`), true, `{"a":-12345678901234567890}`, `{"a":"-12345678901234567890"}`},
+		{[]byte(`{"a":0}`), true, `{"a":0}`, `{"a":"0"}`},
+		// When: Integer is malformed or null
+		// Then: Optional is invalid
+		{[]byte(`{"a":1.5}`), false, `{"a":null}`, `{"a":null}`},
+		{[]byte(`{"a":"0x10"}`), false, `{"a":null}`, `{"a":null}`},
+		{[]byte(`{"a":null}`), false, `{"a":null}`, `{"a":null}`},
+	}
+
+	for _, v := range testCase {
+		k, q := ts{}, qs{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if err := json.Unmarshal(v.message, &q); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if k.A.Ok() != v.ok || q.A.Ok() != v.ok {
+			t.Errorf("[optional] Unexpected validity from message: %s, got: %v %v", v.message, k.A.Ok(), q.A.Ok())
+		}
+		msg, err := json.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		if string(msg) != v.number {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.number, msg)
+		}
+		if msg, err = json.Marshal(q); err != nil || string(msg) != v.quoted {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s, error: %v", v.quoted, msg, err)
+		}
+	}
+
+	// Scenario: Big integer is stored in BSON
+	// Given: Big integer which fits int64 and one which does not
+	for _, s := range []string{"-42", "12345678901234567890"} {
+		i, _ := new(big.Int).SetString(s, 10)
+		k := ts{optional.NewBigInt(i, true)}
+
+		// When: Marshaled using MarshalBSON and mgo, then unmarshaled
+		// Then: Bytes are the same and result matches original data
+		msg, err := optional.MarshalBSON(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %v, error: %v", s, err)
+			continue
+		}
+		expected, _ := bson.Marshal(k)
+		if !bytes.Equal(msg, expected) {
+			t.Errorf("[optional] Unexpected BSON message, expected: %x, got: %x", expected, msg)
+		}
+		u, nu := ts{}, ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if err := optional.UnmarshalBSON(msg, &nu); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if v, ok := u.A.Get(); !ok || v.Cmp(i) != 0 {
+			t.Errorf("[optional] Unexpected unmarshal result, expected: %v, got: %v", i, v)
+		}
+		if v, ok := nu.A.Get(); !ok || v.Cmp(i) != 0 {
+			t.Errorf("[optional] Unexpected unmarshal result, expected: %v, got: %v", i, v)
+		}
+
+		// When: Converted to driver value and scanned back
+		// Then: Result matches original data
+		dv, err := k.A.Value()
+		if err != nil {
+			t.Errorf("[optional] Fail to convert %v, error: %v", s, err)
+			continue
+		}
+		u = ts{}
+		if err := u.A.Scan(dv); err != nil {
+			t.Errorf("[optional] Fail to scan %v, error: %v", dv, err)
+		}
+		if v, ok := u.A.Get(); !ok || v.Cmp(i) != 0 {
+			t.Errorf("[optional] Unexpected scan result, expected: %v, got: %v", i, v)
+		}
+	}
+
+	// Scenario: Fractional BSON number is rejected
+	// Given: Document with fractional double
+	// When: Unmarshaled into big integer
+	// Then: Returns error
+	msg, _ := bson.Marshal(bson.M{"a": 1.5})
+	if err := bson.Unmarshal(msg, &ts{}); err == nil {
+		t.Errorf("[optional] Expected error from fractional number")
+	}
+}
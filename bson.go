@@ -0,0 +1,421 @@
+package goptional
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// bsonKind is kind of BSON element, named in error messages.
+type bsonKind byte
+
+var bsonKindNames = map[bsonKind]string{
+	0x01: "double",
+	0x02: "string",
+	0x03: "document",
+	0x04: "array",
+	0x05: "binary",
+	0x06: "undefined",
+	0x07: "objectId",
+	0x08: "bool",
+	0x09: "datetime",
+	0x0A: "null",
+	0x0B: "regex",
+	0x0C: "dbPointer",
+	0x0D: "javascript",
+	0x0E: "symbol",
+	0x0F: "javascriptWithScope",
+	0x10: "int32",
+	0x11: "timestamp",
+	0x12: "int64",
+	0x13: "decimal128",
+	0x7F: "maxKey",
+	0xFF: "minKey",
+}
+
+func (k bsonKind) String() string {
+	if s, ok := bsonKindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("0x%02X", byte(k))
+}
+
+var (
+	errKind     = errors.New("incompatible kind")
+	errFraction = errors.New("value has fractional part")
+	errOverflow = errors.New("value overflows")
+	errCorrupt  = errors.New("corrupted data")
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// bsonSetter is implemented by optionals which decode BSON element by themselves.
+type bsonSetter interface {
+	setBSON(kind byte, data []byte) error
+}
+
+// getBSON returns value stored in BSON for v with special rules for some types.
+func getBSON(v interface{}) (interface{}, error) {
+	switch i := v.(type) {
+	case *big.Int:
+		return bigIntBSON(i)
+	}
+	if s, ok := text(v); ok {
+		return s, nil
+	}
+	return v, nil
+}
+
+// bsonBinary is BSON binary with its subtype.
+type bsonBinary struct {
+	kind byte
+	data []byte
+}
+
+// GetBSON implements bson.Getter
+func (b bsonBinary) GetBSON() (interface{}, error) {
+	return bson.Binary{Kind: b.kind, Data: b.data}, nil
+}
+
+// GetBSON implements bson.Getter
+func (x decimal128) GetBSON() (interface{}, error) {
+	return bson.ParseDecimal128(x.String())
+}
+
+// bsonError describes failure of decoding BSON element with kind into type t.
+func bsonError(kind byte, t reflect.Type, err error) error {
+	if err == errKind {
+		return fmt.Errorf("Unable to unmarshal data with kind %v to %v", bsonKind(kind), t)
+	}
+	return fmt.Errorf("Unable to unmarshal data with kind %v to %v: %v", bsonKind(kind), t, err)
+}
+
+// decodeBSON decodes value data of BSON element with kind into rv. Types implementing
+// bson.Setter decode themselves, as mgo lets them. Numeric value is widened between BSON
+// int32, int64 and double as long as it fits into rv exactly.
+func decodeBSON(kind byte, data []byte, rv reflect.Value) error {
+	if rv.CanAddr() {
+		if s, ok := rv.Addr().Interface().(bsonSetter); ok {
+			return s.setBSON(kind, data)
+		}
+		if s, ok := rv.Addr().Interface().(bson.Setter); ok {
+			err := s.SetBSON(bson.Raw{Kind: kind, Data: data})
+			if err == bson.SetZero {
+				rv.Set(reflect.Zero(rv.Type()))
+				return nil
+			}
+			return err
+		}
+	}
+	if kind == 0x0A || kind == 0x06 {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeBSON(kind, data, rv.Elem())
+	case reflect.Interface:
+		if rv.NumMethod() > 0 {
+			return bsonError(kind, rv.Type(), errKind)
+		}
+		v, err := decodeBSONAny(kind, data)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			rv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	}
+	if rv.Type() == bigIntType {
+		return decodeBSONBigInt(kind, data, rv)
+	}
+
+	var err error
+	switch kind {
+	case 0x01, 0x10, 0x12:
+		err = decodeBSONNumber(kind, data, rv)
+	case 0x02:
+		var s string
+		if s, err = readBSONString(data); err == nil {
+			err = setBSONString(s, rv)
+		}
+	case 0x07:
+		if len(data) < 12 {
+			err = errCorrupt
+		} else if rv.Kind() != reflect.String {
+			err = errKind
+		} else {
+			rv.SetString(string(data[:12]))
+		}
+	case 0x08:
+		if len(data) < 1 {
+			err = errCorrupt
+		} else if rv.Kind() == reflect.Bool {
+			rv.SetBool(data[0] != 0)
+		} else {
+			err = errKind
+		}
+	case 0x09:
+		if len(data) < 8 {
+			err = errCorrupt
+		} else if rv.Type() != timeType {
+			err = errKind
+		} else {
+			rv.Set(reflect.ValueOf(readBSONTime(data)))
+		}
+	case 0x13:
+		// Decimal and BigInt decode decimal128 by themselves.
+		if _, err = readDecimal128(data); err == nil {
+			err = errKind
+		}
+	case 0x05:
+		var b []byte
+		if b, _, err = readBSONBinary(data); err == nil {
+			if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Uint8 {
+				err = errKind
+			} else {
+				rv.SetBytes(append([]byte{}, b...))
+			}
+		}
+	case 0x03:
+		return decodeBSONDocument(data, rv)
+	case 0x04:
+		return decodeBSONArray(data, rv)
+	default:
+		err = errKind
+	}
+	if err != nil {
+		return bsonError(kind, rv.Type(), err)
+	}
+	return nil
+}
+
+func decodeBSONNumber(kind byte, data []byte, rv reflect.Value) error {
+	size := 8
+	if kind == 0x10 {
+		size = 4
+	}
+	if len(data) < size {
+		return errCorrupt
+	}
+	if !isNumber(rv.Kind()) {
+		return errKind
+	}
+	switch kind {
+	case 0x01:
+		return setFloat(rv, math.Float64frombits(binary.LittleEndian.Uint64(data)))
+	case 0x10:
+		return setInt(rv, int64(int32(binary.LittleEndian.Uint32(data))))
+	}
+	return setInt(rv, int64(binary.LittleEndian.Uint64(data)))
+}
+
+// decodeBSONDocument decodes BSON document data into struct or map rv.
+func decodeBSONDocument(data []byte, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Struct:
+		fields := map[string][]int{}
+		for _, f := range bsonFields(rv.Type()) {
+			fields[f.key] = f.index
+		}
+		return readBSONDocument(data, func(name string, kind byte, value []byte) error {
+			index, ok := fields[name]
+			if !ok {
+				return nil
+			}
+			return decodeBSON(kind, value, rv.FieldByIndex(index))
+		})
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		et := rv.Type().Elem()
+		return readBSONDocument(data, func(name string, kind byte, value []byte) error {
+			e := reflect.New(et).Elem()
+			if err := decodeBSON(kind, value, e); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()), e)
+			return nil
+		})
+	}
+	return bsonError(0x03, rv.Type(), errKind)
+}
+
+// decodeBSONArray decodes BSON array data into slice or array rv.
+func decodeBSONArray(data []byte, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), 0, 0)
+		err := readBSONDocument(data, func(_ string, kind byte, value []byte) error {
+			e := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeBSON(kind, value, e); err != nil {
+				return err
+			}
+			s = reflect.Append(s, e)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rv.Set(s)
+		return nil
+	case reflect.Array:
+		i := 0
+		return readBSONDocument(data, func(_ string, kind byte, value []byte) error {
+			if i >= rv.Len() {
+				return bsonError(0x04, rv.Type(), errOverflow)
+			}
+			i++
+			return decodeBSON(kind, value, rv.Index(i-1))
+		})
+	}
+	return bsonError(0x04, rv.Type(), errKind)
+}
+
+// setBSONString sets s into rv of string kind, parsing it if rv is stored as text or is Duration.
+func setBSONString(s string, rv reflect.Value) error {
+	if rv.CanAddr() {
+		if ok, err := parseText(s, rv.Addr().Interface()); ok {
+			return err
+		}
+	}
+	switch {
+	case rv.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+	case rv.Kind() == reflect.String:
+		rv.SetString(s)
+	default:
+		return errKind
+	}
+	return nil
+}
+
+// decodeBSONAny decodes value data of BSON element with kind into its natural Go type.
+func decodeBSONAny(kind byte, data []byte) (interface{}, error) {
+	var v interface{}
+	switch kind {
+	case 0x0A, 0x06:
+		return nil, nil
+	case 0x01:
+		v = float64(0)
+	case 0x02, 0x07:
+		v = ""
+	case 0x03:
+		v = map[string]interface{}{}
+	case 0x04:
+		v = []interface{}{}
+	case 0x05:
+		v = []byte{}
+	case 0x08:
+		v = false
+	case 0x09:
+		v = time.Time{}
+	case 0x10:
+		v = int(0)
+	case 0x12:
+		v = int64(0)
+	case 0x13:
+		v = Dec{}
+	default:
+		return nil, bsonError(kind, reflect.TypeOf(&v).Elem(), errKind)
+	}
+	rv := reflect.New(reflect.TypeOf(v)).Elem()
+	if err := decodeBSON(kind, data, rv); err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// setInt sets integer n into numeric rv. Float rv only accepts n it represents exactly.
+func setInt(rv reflect.Value, n int64) error {
+	switch k := rv.Kind(); {
+	case isInt(k):
+		if rv.OverflowInt(n) {
+			return errOverflow
+		}
+		rv.SetInt(n)
+	case isUint(k):
+		if n < 0 {
+			return errOverflow
+		}
+		return setUint(rv, uint64(n))
+	default:
+		f := float64(n)
+		if f >= math.MaxInt64 || int64(f) != n || (k == reflect.Float32 && float64(float32(f)) != f) {
+			return errOverflow
+		}
+		rv.SetFloat(f)
+	}
+	return nil
+}
+
+// setFloat sets float f into numeric rv. Integer rv only accepts whole numbers.
+func setFloat(rv reflect.Value, f float64) error {
+	k := rv.Kind()
+	if !isInt(k) && !isUint(k) {
+		if rv.OverflowFloat(f) {
+			return errOverflow
+		}
+		rv.SetFloat(f)
+		return nil
+	}
+	if f != math.Trunc(f) {
+		return errFraction
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		if isUint(k) && f >= 0 && f < math.MaxUint64 {
+			return setUint(rv, uint64(f))
+		}
+		return errOverflow
+	}
+	return setInt(rv, int64(f))
+}
+
+func setUint(rv reflect.Value, n uint64) error {
+	if rv.OverflowUint(n) {
+		return errOverflow
+	}
+	rv.SetUint(n)
+	return nil
+}
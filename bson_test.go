@@ -0,0 +1,206 @@
+package optional_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestBSONNumeric(t *testing.T) {
+	type ti struct {
+		A optional.Int `bson:"a"`
+	}
+	type ti64 struct {
+		A optional.Int64 `bson:"a"`
+	}
+	type tf struct {
+		A optional.Float64 `bson:"a"`
+	}
+	type tb struct {
+		A optional.Bool `bson:"a"`
+	}
+
+	// Scenario: Numeric BSON values are widened into numeric optionals
+	// Given: Document with numeric value of every BSON kind
+	testCase := []struct {
+		doc      bson.M
+		out      interface{}
+		expected interface{}
+		err      string
+	}{
+		// When: Value fits target exactly
+		// Then: Value is widened
+		{bson.M{"a": int32(5)}, &ti{}, &ti{optional.NewInt(5, true)}, ""},
+		{bson.M{"a": int64(-5)}, &ti{}, &ti{optional.NewInt(-5, true)}, ""},
+		{bson.M{"a": float64(6)}, &ti{}, &ti{optional.NewInt(6, true)}, ""},
+		{bson.M{"a": int32(7)}, &ti64{}, &ti64{optional.NewInt64(7, true)}, ""},
+		{bson.M{"a": float64(1 << 40)}, &ti64{}, &ti64{optional.NewInt64(1<<40, true)}, ""},
+		{bson.M{"a": int32(8)}, &tf{}, &tf{optional.NewFloat64(8, true)}, ""},
+		{bson.M{"a": int64(1 << 50)}, &tf{}, &tf{optional.NewFloat64(1<<50, true)}, ""},
+		{bson.M{"a": true}, &tb{}, &tb{optional.NewBool(true, true)}, ""},
+		{bson.M{"a": nil}, &tb{}, &tb{}, ""},
+		// When: Value does not fit target
+		// Then: Reports error naming BSON kind and target type
+		{bson.M{"a": 1.5}, &ti{}, nil, "Unable to unmarshal data with kind double to int: value has fractional part"},
+		{bson.M{"a": math.MaxFloat64}, &ti64{}, nil, "Unable to unmarshal data with kind double to int64: value overflows"},
+		{bson.M{"a": "1"}, &ti{}, nil, "Unable to unmarshal data with kind string to int"},
+		{bson.M{"a": "1"}, &tf{}, nil, "Unable to unmarshal data with kind string to float64"},
+		{bson.M{"a": "true"}, &tb{}, nil, "Unable to unmarshal data with kind string to bool"},
+	}
+
+	for _, v := range testCase {
+		msg, err := bson.Marshal(v.doc)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", v.doc, err)
+			continue
+		}
+		err = bson.Unmarshal(msg, v.out)
+		if v.err != "" {
+			if err == nil || err.Error() != v.err {
+				t.Errorf("[optional] Unexpected error from %v, expected: %s, got: %v", v.doc, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[optional] Fail to unmarshal %v, error: %v", v.doc, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.out, v.expected) {
+			t.Errorf("[optional] Unexpected unmarshal result, expected: %v, got: %v", v.expected, v.out)
+		}
+	}
+}
+
+func TestBSONNarrow(t *testing.T) {
+	type tn struct {
+		A optional.Int8    `bson:"a"`
+		B optional.Uint16  `bson:"b"`
+		C optional.Uint32  `bson:"c"`
+		D optional.Float32 `bson:"d"`
+		E optional.Uint64  `bson:"e"`
+		F optional.Float64 `bson:"f"`
+	}
+
+	// Scenario: Values beyond width of narrow optionals are rejected
+	// Given: Document with value for a single field
+	testCase := []struct {
+		doc bson.M
+		err bool
+	}{
+		// When: Value fits
+		// Then: No error is reported
+		{bson.M{"a": -128, "b": 65535, "c": int64(4294967295), "d": 1.5, "e": float64(1 << 62), "f": int64(1 << 53)}, false},
+		{bson.M{"d": 1 << 24}, false},
+		// When: Value does not fit
+		// Then: Error is reported
+		{bson.M{"a": 128}, true},
+		{bson.M{"a": 1.5}, true},
+		{bson.M{"b": -1}, true},
+		{bson.M{"b": 65536}, true},
+		{bson.M{"c": int64(4294967296)}, true},
+		{bson.M{"d": math.MaxFloat64}, true},
+		{bson.M{"e": -1}, true},
+		{bson.M{"f": int64(1<<53 + 1)}, true},
+		{bson.M{"d": 1<<24 + 1}, true},
+	}
+
+	for _, v := range testCase {
+		msg, err := bson.Marshal(v.doc)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", v.doc, err)
+			continue
+		}
+		var k tn
+		if err := bson.Unmarshal(msg, &k); (err != nil) != v.err {
+			t.Errorf("[optional] Unexpected unmarshal result of %v, error: %v", v.doc, err)
+		}
+	}
+}
+
+func TestBSONBoolNumber(t *testing.T) {
+	type tb struct {
+		B optional.Bool `bson:"b"`
+		I optional.Int  `bson:"i"`
+	}
+	defer func(c optional.Coercer) { optional.Coercion = c }(optional.Coercion)
+
+	// Scenario: Numbers and bools are converted into each other only by Coercion
+	// Given: Document with number for bool and bool for number
+	testCase := []struct {
+		coercion optional.Coercer
+		doc      bson.M
+		expected tb
+		err      bool
+	}{
+		{optional.StrictCoercion{}, bson.M{"b": 42}, tb{}, true},
+		{optional.StrictCoercion{}, bson.M{"b": 1.0}, tb{}, true},
+		{optional.StrictCoercion{}, bson.M{"i": true}, tb{}, true},
+		{optional.LenientCoercion{}, bson.M{"b": 1}, tb{B: optional.NewBool(true, true)}, false},
+		{optional.LenientCoercion{}, bson.M{"b": int64(0)}, tb{B: optional.NewBool(false, true)}, false},
+		{optional.LenientCoercion{}, bson.M{"i": true}, tb{}, true},
+	}
+
+	for _, v := range testCase {
+		// When: Unmarshaled with the Coercion
+		// Then: Value is converted only if the Coercion converts it
+		optional.Coercion = v.coercion
+		msg, err := bson.Marshal(v.doc)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %v, error: %v", v.doc, err)
+			continue
+		}
+		var k tb
+		if err := bson.Unmarshal(msg, &k); (err != nil) != v.err {
+			t.Errorf("[optional] Unexpected unmarshal result of %v with %T, error: %v", v.doc, v.coercion, err)
+			continue
+		}
+		if k != v.expected {
+			t.Errorf("[optional] Unexpected result of %v with %T, expected: %+v, got: %+v", v.doc, v.coercion, v.expected, k)
+		}
+	}
+}
+
+type email string
+
+func (e *email) SetBSON(raw bson.Raw) error {
+	var s string
+	if err := raw.Unmarshal(&s); err != nil {
+		return err
+	}
+	*e = email(strings.ToLower(s))
+	return nil
+}
+
+func TestBSONSetter(t *testing.T) {
+	type te struct {
+		A email                    `bson:"a"`
+		B optional.Of[email]       `bson:"b"`
+		C optional.Nullable[email] `bson:"c"`
+		D optional.SliceOf[email]  `bson:"d"`
+	}
+
+	// Scenario: Values implementing bson.Setter decode themselves inside optionals
+	// Given: Document with mixed-case emails
+	msg, err := bson.Marshal(bson.M{"a": "ABC", "b": "ABC", "c": "ABC", "d": []string{"ABC"}})
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data, error: %v", err)
+	}
+
+	// When: Unmarshaled into plain and optional fields
+	// Then: Every field holds the value set by SetBSON
+	var k te
+	if err := bson.Unmarshal(msg, &k); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message, error: %v", err)
+	}
+	b, _ := k.B.Get()
+	c, _ := k.C.Get()
+	d, _ := k.D.Get()
+	if k.A != "abc" || b != "abc" || c != "abc" || len(d) != 1 || d[0] != "abc" {
+		t.Errorf("[optional] Unexpected result: %+v", k)
+	}
+}
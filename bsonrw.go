@@ -0,0 +1,375 @@
+package goptional
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MarshalBSON returns BSON document of v, a struct or a map with string keys. Optionals and Go
+// basic types are encoded byte for byte the same way gopkg.in/mgo.v2/bson does through GetBSON,
+// without depending on it. Map keys are sorted and, unlike mgo, omitempty consults IsZero method
+// of the field if there is any.
+func MarshalBSON(v interface{}) ([]byte, error) {
+	return appendBSONDocument(nil, reflect.ValueOf(v))
+}
+
+// UnmarshalBSON decodes BSON document data into v, a pointer to struct or map with string keys.
+// Decimal128 decoded into interface{} is Dec.
+func UnmarshalBSON(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("Unable to unmarshal data into non-pointer %T", v)
+	}
+	return decodeBSON(0x03, data, rv.Elem())
+}
+
+// bsonGetter has the same method as bson.Getter.
+type bsonGetter interface {
+	GetBSON() (interface{}, error)
+}
+
+// bsonField describes struct field in BSON document.
+type bsonField struct {
+	key       string
+	index     []int
+	omitEmpty bool
+	minSize   bool
+}
+
+// bsonFields lists fields of struct t following the rules of mgo: key is the bson tag or the
+// lowercased field name, and struct fields tagged with inline are flattened.
+func bsonFields(t reflect.Type) []bsonField {
+	var fields []bsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("bson")
+		if tag == "" && !strings.Contains(string(f.Tag), ":") {
+			tag = string(f.Tag)
+		}
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		field := bsonField{key: opts[0], index: []int{i}}
+		inline := false
+		for _, o := range opts[1:] {
+			switch o {
+			case "omitempty":
+				field.omitEmpty = true
+			case "minsize":
+				field.minSize = true
+			case "inline":
+				inline = true
+			}
+		}
+		if inline && f.Type.Kind() == reflect.Struct {
+			for _, sf := range bsonFields(f.Type) {
+				sf.index = append([]int{i}, sf.index...)
+				fields = append(fields, sf)
+			}
+			continue
+		}
+		if field.key == "" {
+			field.key = strings.ToLower(f.Name)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func appendBSONDocument(dst []byte, v reflect.Value) ([]byte, error) {
+	for v.IsValid() {
+		if g, ok := v.Interface().(bsonGetter); ok {
+			gv, err := g.GetBSON()
+			if err != nil {
+				return nil, err
+			}
+			v = reflect.ValueOf(gv)
+			continue
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, fmt.Errorf("Unable to marshal nil as a BSON document")
+	}
+
+	start := len(dst)
+	dst = append(dst, 0, 0, 0, 0)
+	var err error
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range bsonFields(v.Type()) {
+			fv := v.FieldByIndex(f.index)
+			if f.omitEmpty && isEmptyBSON(fv) {
+				continue
+			}
+			if dst, err = appendBSONElement(dst, f.key, fv, f.minSize); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Unable to marshal %v as a BSON document", v.Type())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			if dst, err = appendBSONElement(dst, k.String(), v.MapIndex(k), false); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if dst, err = appendBSONElement(dst, strconv.Itoa(i), v.Index(i), false); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("Unable to marshal %v as a BSON document", v.Type())
+	}
+	dst = append(dst, 0)
+	binary.LittleEndian.PutUint32(dst[start:], uint32(len(dst)-start))
+	return dst, nil
+}
+
+func appendBSONElement(dst []byte, name string, v reflect.Value, minSize bool) ([]byte, error) {
+	if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return appendBSONName(dst, 0x0A, name), nil
+	}
+	// Values returned by GetBSON of this package are written directly rather than through mgo.
+	switch s := v.Interface().(type) {
+	case bsonBinary:
+		return appendBSONBinary(appendBSONName(dst, 0x05, name), s.kind, s.data), nil
+	case decimal128:
+		dst = appendBSONName(dst, 0x13, name)
+		dst = binary.LittleEndian.AppendUint64(dst, s.lo)
+		return binary.LittleEndian.AppendUint64(dst, s.hi), nil
+	}
+	if g, ok := v.Interface().(bsonGetter); ok {
+		gv, err := g.GetBSON()
+		if err != nil {
+			return nil, err
+		}
+		return appendBSONElement(dst, name, reflect.ValueOf(gv), minSize)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return appendBSONElement(dst, name, v.Elem(), minSize)
+	case reflect.String:
+		dst = appendBSONName(dst, 0x02, name)
+		return appendBSONString(dst, v.String()), nil
+	case reflect.Float32, reflect.Float64:
+		dst = appendBSONName(dst, 0x01, name)
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(v.Float())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if int64(u) < 0 {
+			return nil, fmt.Errorf("Unable to marshal %v, value is too large for int64", u)
+		}
+		if u <= math.MaxInt32 && (minSize || v.Kind() <= reflect.Uint32) {
+			dst = appendBSONName(dst, 0x10, name)
+			return binary.LittleEndian.AppendUint32(dst, uint32(u)), nil
+		}
+		dst = appendBSONName(dst, 0x12, name)
+		return binary.LittleEndian.AppendUint64(dst, u), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if (minSize || v.Kind() != reflect.Int64) && i >= math.MinInt32 && i <= math.MaxInt32 {
+			dst = appendBSONName(dst, 0x10, name)
+			return binary.LittleEndian.AppendUint32(dst, uint32(int32(i))), nil
+		}
+		dst = appendBSONName(dst, 0x12, name)
+		return binary.LittleEndian.AppendUint64(dst, uint64(i)), nil
+	case reflect.Bool:
+		dst = appendBSONName(dst, 0x08, name)
+		if v.Bool() {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case reflect.Map:
+		return appendBSONDocument(appendBSONName(dst, 0x03, name), v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return appendBSONBinary(appendBSONName(dst, 0x05, name), 0x00, b), nil
+		}
+		return appendBSONDocument(appendBSONName(dst, 0x04, name), v)
+	case reflect.Struct:
+		switch s := v.Interface().(type) {
+		case time.Time:
+			dst = appendBSONName(dst, 0x09, name)
+			return binary.LittleEndian.AppendUint64(dst, uint64(s.Unix()*1000+int64(s.Nanosecond()/1e6))), nil
+		case url.URL:
+			dst = appendBSONName(dst, 0x02, name)
+			return appendBSONString(dst, s.String()), nil
+		}
+		return appendBSONDocument(appendBSONName(dst, 0x03, name), v)
+	}
+	return nil, fmt.Errorf("Unable to marshal %v in a BSON document", v.Type())
+}
+
+// isEmptyBSON follows the rule used by mgo for omitempty, except that IsZero method is consulted.
+func isEmptyBSON(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			if !isEmptyBSON(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return isEmptyValue(v)
+}
+
+func appendBSONName(dst []byte, kind byte, name string) []byte {
+	dst = append(dst, kind)
+	dst = append(dst, name...)
+	return append(dst, 0)
+}
+
+func appendBSONString(dst []byte, s string) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(s)+1))
+	dst = append(dst, s...)
+	return append(dst, 0)
+}
+
+func appendBSONBinary(dst []byte, subtype byte, b []byte) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(b)))
+	dst = append(dst, subtype)
+	return append(dst, b...)
+}
+
+func readBSONString(data []byte) (string, error) {
+	if len(data) < 5 {
+		return "", errCorrupt
+	}
+	n := int(int32(binary.LittleEndian.Uint32(data)))
+	if n < 1 || 4+n > len(data) || data[4+n-1] != 0 {
+		return "", errCorrupt
+	}
+	return string(data[4 : 4+n-1]), nil
+}
+
+func readBSONBinary(data []byte) ([]byte, byte, error) {
+	if len(data) < 5 {
+		return nil, 0, errCorrupt
+	}
+	n := int(int32(binary.LittleEndian.Uint32(data)))
+	if n < 0 || 5+n > len(data) {
+		return nil, 0, errCorrupt
+	}
+	b, subtype := data[5:5+n], data[4]
+	if subtype == 0x02 && len(b) >= 4 {
+		// Obsolete binary subtype carries redundant length.
+		b = b[4:]
+	}
+	return b, subtype, nil
+}
+
+// readBSONTime reads datetime, which MongoDB handles as milliseconds.
+func readBSONTime(data []byte) time.Time {
+	i := int64(binary.LittleEndian.Uint64(data))
+	if i == -62135596800000 {
+		return time.Time{}
+	}
+	return time.Unix(i/1e3, i%1e3*1e6)
+}
+
+// readBSONDocument calls fn with every element of BSON document data.
+func readBSONDocument(data []byte, fn func(name string, kind byte, value []byte) error) error {
+	if len(data) < 5 {
+		return errCorrupt
+	}
+	n := int(int32(binary.LittleEndian.Uint32(data)))
+	if n < 5 || n > len(data) || data[n-1] != 0 {
+		return errCorrupt
+	}
+	b := data[4 : n-1]
+	for len(b) > 0 {
+		kind := b[0]
+		i := bytes.IndexByte(b[1:], 0)
+		if i < 0 {
+			return errCorrupt
+		}
+		name := string(b[1 : 1+i])
+		b = b[2+i:]
+		size, err := bsonValueSize(kind, b)
+		if err != nil {
+			return err
+		}
+		if err := fn(name, kind, b[:size]); err != nil {
+			return err
+		}
+		b = b[size:]
+	}
+	return nil
+}
+
+// bsonValueSize returns size of value of BSON element with kind at the start of b.
+func bsonValueSize(kind byte, b []byte) (int, error) {
+	size := 0
+	switch kind {
+	case 0x06, 0x0A, 0x7F, 0xFF:
+	case 0x08:
+		size = 1
+	case 0x10:
+		size = 4
+	case 0x01, 0x09, 0x11, 0x12:
+		size = 8
+	case 0x07:
+		size = 12
+	case 0x13:
+		size = 16
+	case 0x02, 0x0D, 0x0E, 0x03, 0x04, 0x0F, 0x05, 0x0C:
+		if len(b) < 4 {
+			return 0, errCorrupt
+		}
+		size = int(int32(binary.LittleEndian.Uint32(b)))
+		switch kind {
+		case 0x02, 0x0D, 0x0E:
+			size += 4
+		case 0x05:
+			size += 5
+		case 0x0C:
+			size += 4 + 12
+		}
+	case 0x0B:
+		i := bytes.IndexByte(b, 0)
+		if i < 0 {
+			return 0, errCorrupt
+		}
+		j := bytes.IndexByte(b[i+1:], 0)
+		if j < 0 {
+			return 0, errCorrupt
+		}
+		size = i + j + 2
+	default:
+		return 0, fmt.Errorf("Unknown element kind (0x%02X)", kind)
+	}
+	if size < 0 || size > len(b) {
+		return 0, errCorrupt
+	}
+	return size, nil
+}
@@ -0,0 +1,149 @@
+package optional_test
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+type TestE struct {
+	A optional.Int              `bson:"a"`
+	B optional.Int64            `bson:"b"`
+	C optional.String           `bson:"c"`
+	D optional.Float64          `bson:"d"`
+	E optional.Bool             `bson:"e"`
+	F optional.Of[time.Time]    `bson:"f"`
+	G optional.Nullable[string] `bson:"g"`
+	H []optional.Int64          `bson:"h"`
+	I int32                     `bson:"i,omitempty"`
+	J struct {
+		K optional.String
+		L []byte
+	} `bson:"j"`
+	M map[string]optional.Int `bson:"m"`
+}
+
+func TestBSONNative(t *testing.T) {
+	now := time.Unix(1500000000, 123000000)
+
+	// Scenario: Native BSON encoder is compatible with mgo
+	// Given: Struct with every kind of optional and basic values
+	testCase := []TestE{
+		{},
+		{
+			A: optional.NewInt(1, true), B: optional.NewInt64(2, true), C: optional.NewString("c", true),
+			D: optional.NewFloat64(4.5, true), E: optional.NewBool(true, true), F: optional.New(now, true),
+			G: optional.Null[string](), H: []optional.Int64{optional.NewInt64(1, true), {}}, I: 9,
+			M: map[string]optional.Int{"x": optional.NewInt(1<<40, true)},
+		},
+		{
+			A: optional.NewInt(0, true), B: optional.NewInt64(-1<<40, true), C: optional.NewString("", true),
+			D: optional.NewFloat64(0, true), E: optional.NewBool(false, true), G: optional.NewNullable("g", true),
+			H: []optional.Int64{},
+		},
+	}
+	testCase[2].J.K = optional.NewString("k", true)
+	testCase[2].J.L = []byte{1, 2, 3}
+
+	for _, v := range testCase {
+		// When: Marshaled using MarshalBSON and mgo
+		// Then: Returns the same bytes
+		msg, err := optional.MarshalBSON(v)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", v, err)
+			continue
+		}
+		expected, err := bson.Marshal(v)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v with mgo, error: %v", v, err)
+			continue
+		}
+		if !bytes.Equal(msg, expected) {
+			t.Errorf("[optional] Unexpected BSON message, expected: %x, got: %x", expected, msg)
+		}
+
+		// When: Unmarshaled using UnmarshalBSON and mgo
+		// Then: Returns the same data
+		var u, mu TestE
+		if err := optional.UnmarshalBSON(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if err := bson.Unmarshal(msg, &mu); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x with mgo, error: %v", msg, err)
+			continue
+		}
+		if !reflect.DeepEqual(u, mu) {
+			t.Errorf("[optional] Unmarshal BSON mismatch, got: %v, expected: %v", u, mu)
+		}
+	}
+
+	// Scenario: Document is decoded into generic map
+	// Given: Document with nested values
+	msg, err := optional.MarshalBSON(map[string]interface{}{
+		"a": optional.NewInt(1, true), "b": optional.String{}, "c": []interface{}{"x", 1.5},
+	})
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal map, error: %v", err)
+	}
+	// When: Unmarshaled into map
+	// Then: Values have their natural types
+	m := map[string]interface{}{}
+	if err := optional.UnmarshalBSON(msg, &m); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+	}
+	expected := map[string]interface{}{"a": 1, "b": nil, "c": []interface{}{"x", 1.5}}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("[optional] Unexpected unmarshal result, expected: %v, got: %v", expected, m)
+	}
+
+	// Scenario: Decimal128 and binary are encoded without mgo
+	// Given: Decimals of every magnitude, big integers and UUID
+	decCase := []string{"0", "1.50", "-0.001", "12345678901234567890.1234567890123", "1e40", "-1e-6000"}
+	for _, s := range decCase {
+		d, _ := optional.ParseDec(s)
+		big, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+		v := struct {
+			D optional.Dec    `bson:"d"`
+			I optional.BigInt `bson:"i"`
+			U optional.UUID   `bson:"u"`
+		}{d, optional.NewBigInt(big, true), optional.NewUUID(optional.UUIDValue{1, 2}, true)}
+
+		// When: Marshaled using MarshalBSON and mgo
+		// Then: Returns the same bytes
+		msg, err := optional.MarshalBSON(v)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal decimal %v, error: %v", s, err)
+			continue
+		}
+		if expected, _ := bson.Marshal(v); !bytes.Equal(msg, expected) {
+			t.Errorf("[optional] Unexpected BSON message, expected: %x, got: %x", expected, msg)
+		}
+
+		// When: Unmarshaled into interface{}
+		// Then: Decimal128 is decoded as Dec of the same value
+		m := map[string]interface{}{}
+		if err := optional.UnmarshalBSON(msg, &m); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+		} else if got, ok := m["d"].(optional.Dec); !ok || got.Rat().Cmp(d.Rat()) != 0 {
+			t.Errorf("[optional] Unexpected decimal, expected: %v, got: %v", d, m["d"])
+		}
+	}
+
+	// Scenario: Mismatching type is reported
+	// Given: Document with string value
+	msg, _ = optional.MarshalBSON(map[string]string{"a": "abc"})
+	// When: Unmarshaled into numeric optional
+	// Then: Returns error
+	var u TestE
+	err = optional.UnmarshalBSON(msg, &u)
+	if err == nil || err.Error() != "Unable to unmarshal data with kind string to int" {
+		t.Errorf("[optional] Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package goptional
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Encoding represents bytes as JSON string for BytesAs.
+type Encoding interface {
+	EncodeToString(src []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+// Base64 represents bytes as standard base64 string, the same as encoding/json does.
+type Base64 struct{}
+
+// EncodeToString implements Encoding
+func (Base64) EncodeToString(src []byte) string {
+	return base64.StdEncoding.EncodeToString(src)
+}
+
+// DecodeString implements Encoding
+func (Base64) DecodeString(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// Hex represents bytes as lowercase hexadecimal string.
+type Hex struct{}
+
+// EncodeToString implements Encoding
+func (Hex) EncodeToString(src []byte) string {
+	return hex.EncodeToString(src)
+}
+
+// DecodeString implements Encoding
+func (Hex) DecodeString(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+// BytesAs is optional form of []byte represented in JSON using zero value of E, e.g.
+// BytesAs[Hex] for hexadecimal string. Valid optional holding empty slice is distinct from
+// invalid one, and it is stored in BSON as binary of generic subtype.
+//
+// BytesAs wraps Of[[]byte] in an unexported field rather than aliasing it, so that its value
+// is only set and read through methods which copy the slice and the optional does not share
+// memory with its caller.
+type BytesAs[E Encoding] struct {
+	opt[[]byte]
+}
+
+// Bytes is BytesAs represented in JSON as base64 string.
+type Bytes = BytesAs[Base64]
+
+// NewBytes creates a new optional
+func NewBytes(val []byte, ok bool) Bytes {
+	return NewBytesAs[Base64](val, ok)
+}
+
+// NewBytesAs creates a new optional
+func NewBytesAs[E Encoding](val []byte, ok bool) BytesAs[E] {
+	return BytesAs[E]{opt[[]byte]{cloneBytes(val), ok}}
+}
+
+// Get returns copy of value-flag pair of this optional.
+func (b BytesAs[E]) Get() ([]byte, bool) {
+	return cloneBytes(b.val), b.set
+}
+
+// Set sets copy of value-flag pair of this optional.
+func (b *BytesAs[E]) Set(v []byte, s bool) {
+	b.val = cloneBytes(v)
+	b.set = s
+}
+
+// cloneBytes copies b while keeping nil and empty slice distinct.
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// UnmarshalJSON is used to unmarshal JSON string encoded with E into optional value.
+// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false)
+// and error is returned only in Strict mode.
+func (b *BytesAs[E]) UnmarshalJSON(dt []byte) error {
+	if bytes.Equal(dt, []byte("null")) {
+		b.set = false
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(dt, &s)
+	if err == nil {
+		var e E
+		b.val, err = e.DecodeString(s)
+	}
+	if err != nil {
+		b.set = false
+		return unmarshalError(dt, &b.val, err)
+	}
+	b.set = true
+	return nil
+}
+
+// MarshalJSON marshals optional into JSON string encoded with E.
+// Invalid optional is marshaled as null.
+func (b BytesAs[E]) MarshalJSON() ([]byte, error) {
+	if !b.set {
+		return []byte("null"), nil
+	}
+	var e E
+	return json.Marshal(e.EncodeToString(b.val))
+}
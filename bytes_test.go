@@ -0,0 +1,95 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestBytes(t *testing.T) {
+	type ts struct {
+		A optional.Bytes                 `json:"a" bson:"a"`
+		H optional.BytesAs[optional.Hex] `json:"h" bson:"h"`
+	}
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	// Scenario: Marshal bytes to JSON then unmarshal again should retain data
+	// Given: Bytes optional which is invalid, empty or holding data
+	testCase := []struct {
+		data     ts
+		expected string
+	}{
+		{ts{}, `{"a":null,"h":null}`},
+		{ts{A: optional.NewBytes([]byte{}, true)}, `{"a":"","h":null}`},
+		{ts{A: optional.NewBytes(data, true), H: optional.NewBytesAs[optional.Hex](data, true)}, `{"a":"3q2+7w==","h":"deadbeef"}`},
+	}
+
+	for _, v := range testCase {
+		// When: Marshaled and unmarshaled with encoding of each field
+		// Then: JSON is encoded and result matches original data
+		msg, err := json.Marshal(v.data)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", v.data, err)
+			continue
+		}
+		if string(msg) != v.expected {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.expected, msg)
+		}
+		var u ts
+		if err := json.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", msg, err)
+			continue
+		}
+		assertBytes(t, v.data.A, u.A)
+		assertBytes(t, optional.NewBytes(v.data.H.Get()), optional.NewBytes(u.H.Get()))
+
+		// When: Marshaled and unmarshaled using mgo
+		// Then: Result matches original data
+		msg, err = bson.Marshal(v.data)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", v.data, err)
+			continue
+		}
+		u = ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		assertBytes(t, v.data.A, u.A)
+		assertBytes(t, optional.NewBytes(v.data.H.Get()), optional.NewBytes(u.H.Get()))
+	}
+
+	// Scenario: Malformed string is rejected
+	// Given: JSON string which is not hex
+	// When: Unmarshaled with Hex encoding
+	// Then: Optional is invalid
+	var u ts
+	if err := json.Unmarshal([]byte(`{"h":"xyz"}`), &u); err != nil || u.H.Ok() {
+		t.Errorf("[optional] Unexpected unmarshal result: %v, error: %v", u.H, err)
+	}
+
+	// Scenario: Optional does not share memory with caller
+	// Given: Bytes optional created from a slice
+	// When: Slice passed to Set and slice returned by Get are modified
+	// Then: Optional value is unchanged
+	b := []byte{1, 2, 3}
+	u.A.Set(b, true)
+	b[0] = 9
+	g, _ := u.A.Get()
+	g[1] = 9
+	if g, _ := u.A.Get(); !bytes.Equal(g, []byte{1, 2, 3}) {
+		t.Errorf("[optional] Unexpected shared memory, got: %v", g)
+	}
+}
+
+func assertBytes(t *testing.T, expected, got optional.Bytes) {
+	e, eok := expected.Get()
+	g, gok := got.Get()
+	if eok != gok || !bytes.Equal(e, g) || (e == nil) != (g == nil) {
+		t.Errorf("[optional] Unexpected bytes, expected: %v %v, got: %v %v", e, eok, g, gok)
+	}
+}
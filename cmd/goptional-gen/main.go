@@ -0,0 +1,119 @@
+// Command goptional-gen generates optional forms of user-defined types as aliases of
+// goptional.Of, so that they have every method of goptional optionals, including Coercion
+// and widening of numbers, and delegate to marshalers of the wrapped type.
+//
+// It is meant to be run by go generate, for example:
+//
+//	//go:generate goptional-gen -type Money,Email
+//
+// generates OptionalMoney and OptionalEmail into money_optional.go of the package.
+//
+// Flags:
+//
+//	-type     comma-separated list of type names, required
+//	-prefix   prefix of generated type names, "Optional" by default
+//	-package  package name, $GOPACKAGE by default
+//	-output   output file name, <first type in lowercase>_optional.go by default
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	log := func(err error) {
+		fmt.Fprintf(os.Stderr, "goptional-gen: %v\n", err)
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("goptional-gen", flag.ExitOnError)
+	types := fs.String("type", "", "comma-separated list of type names")
+	prefix := fs.String("prefix", "Optional", "prefix of generated type names")
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "package name")
+	output := fs.String("output", "", "output file name")
+	fs.Parse(os.Args[1:])
+
+	c := config{Package: *pkg, Prefix: *prefix}
+	for _, t := range strings.Split(*types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			c.Types = append(c.Types, t)
+		}
+	}
+	if *output == "" && len(c.Types) > 0 {
+		*output = strings.ToLower(c.Types[0]) + "_optional.go"
+	}
+	src, err := generate(c)
+	if err != nil {
+		log(err)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log(err)
+	}
+}
+
+// config describes what to generate.
+type config struct {
+	Package string
+	Prefix  string
+	Types   []string
+}
+
+// generate returns formatted source of optionals described by c.
+func generate(c config) ([]byte, error) {
+	if c.Package == "" {
+		return nil, fmt.Errorf("package name is not given, run through go generate or set -package")
+	}
+	if len(c.Types) == 0 {
+		return nil, fmt.Errorf("type names are not given, set -type")
+	}
+	for _, t := range c.Types {
+		if !token.IsIdentifier(t) {
+			return nil, fmt.Errorf("invalid type name %q", t)
+		}
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, c); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code: %v", err)
+	}
+	return src, nil
+}
+
+func write(w io.Writer, c config) error {
+	if err := header.Execute(w, c); err != nil {
+		return err
+	}
+	for _, t := range c.Types {
+		if err := optional.Execute(w, struct{ Name, Type string }{c.Prefix + t, t}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var header = template.Must(template.New("header").Parse(`// Code generated by goptional-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/rahmatismail/goptional"
+`))
+
+var optional = template.Must(template.New("optional").Parse(`
+// {{.Name}} is optional form of {{.Type}}.
+type {{.Name}} = goptional.Of[{{.Type}}]
+
+// New{{.Name}} creates a new optional
+func New{{.Name}}(val {{.Type}}, ok bool) {{.Name}} {
+	return goptional.New(val, ok)
+}
+`))
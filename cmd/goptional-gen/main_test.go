@@ -0,0 +1,78 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	// Scenario: Optionals are generated for user-defined types
+	// Given: Package name and type names declared in the package
+	// When: Code is generated
+	src, err := generate(config{Package: "example", Prefix: "Optional", Types: []string{"Money", "Email"}})
+	if err != nil {
+		t.Fatalf("[optional] Fail to generate code, error: %v", err)
+	}
+	if !strings.HasPrefix(string(src), "// Code generated by goptional-gen. DO NOT EDIT.") {
+		t.Errorf("[optional] Generated code lacks header")
+	}
+
+	// Then: Code type-checks along with the package
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, s := range map[string]string{
+		"money_optional.go": string(src),
+		"money.go":          "package example\n\ntype Money int64\n\ntype Email string\n",
+	} {
+		f, err := parser.ParseFile(fset, name, s, 0)
+		if err != nil {
+			t.Fatalf("[optional] Fail to parse %s, error: %v\n%s", name, err, s)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("example", fset, files, nil)
+	if err != nil {
+		t.Fatalf("[optional] Generated code does not type-check, error: %v\n%s", err, src)
+	}
+
+	// Then: Every type is goptional.Of of the type and has a constructor
+	for _, v := range []struct{ name, typ string }{{"OptionalMoney", "Money"}, {"OptionalEmail", "Email"}} {
+		o := pkg.Scope().Lookup(v.name)
+		if o == nil {
+			t.Errorf("[optional] Missing type %s", v.name)
+			continue
+		}
+		typ := types.Unalias(o.Type())
+		if expected := "github.com/rahmatismail/goptional.Of[example." + v.typ + "]"; typ.String() != expected {
+			t.Errorf("[optional] Unexpected type of %s, expected: %s, got: %v", v.name, expected, typ)
+		}
+		fn, ok := pkg.Scope().Lookup("New" + v.name).(*types.Func)
+		if !ok {
+			t.Errorf("[optional] Missing constructor of %s", v.name)
+			continue
+		}
+		if res := fn.Type().(*types.Signature).Results(); res.Len() != 1 || !types.Identical(res.At(0).Type(), typ) {
+			t.Errorf("[optional] Unexpected constructor of %s: %v", v.name, fn.Type())
+		}
+	}
+
+	// Scenario: Missing or invalid configuration is reported
+	// Given: Configuration without package, without types or with invalid type
+	// When: Code is generated
+	// Then: Returns error
+	for _, c := range []config{
+		{Types: []string{"Money"}},
+		{Package: "example"},
+		{Package: "example", Types: []string{"a.Money"}},
+	} {
+		if _, err := generate(c); err == nil {
+			t.Errorf("[optional] Expected error from config: %+v", c)
+		}
+	}
+}
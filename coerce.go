@@ -0,0 +1,211 @@
+package goptional
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Coercer converts src, a value decoded from JSON, BSON or SQL which does not match
+// an optional, into dst, a pointer to value of the optional.
+//
+// src is given in its natural Go type: JSON numbers as json.Number, BSON elements as
+// decoded into interface{}, and SQL values as returned by the driver.
+type Coercer interface {
+	Coerce(src interface{}, dst interface{}) error
+}
+
+// StrictCoercion converts nothing, so that only values matching the optional are decoded.
+type StrictCoercion struct{}
+
+// Coerce implements Coercer
+func (StrictCoercion) Coerce(src interface{}, dst interface{}) error {
+	return errKind
+}
+
+// LenientCoercion converts quoted numbers into numeric optionals, numbers into String,
+// and "true", "false", "yes", "no", "on", "off", 1 and 0 into bool optionals.
+// Surrounding whitespace of text is ignored and numbers still have to fit exactly.
+type LenientCoercion struct{}
+
+// Coerce implements Coercer
+func (LenientCoercion) Coerce(src interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst).Elem()
+	s, isNum := numberText(src)
+	if !isNum {
+		switch v := src.(type) {
+		case string:
+			s = strings.TrimSpace(v)
+		case []byte:
+			s = strings.TrimSpace(string(v))
+		default:
+			return errKind
+		}
+	}
+	switch k := rv.Kind(); {
+	case k == reflect.Bool:
+		b, ok := boolText[strings.ToLower(s)]
+		if !ok {
+			return errKind
+		}
+		rv.SetBool(b)
+	case isNumber(k) && !isNum:
+		return setNumberText(s, rv)
+	case k == reflect.String && isNum:
+		rv.SetString(s)
+	default:
+		return errKind
+	}
+	return nil
+}
+
+// CoerceFunc is a Coercer defined by a function, e.g. to extend LenientCoercion. It is meant
+// to be assigned to Coercion, since Coerced cannot use a nil CoerceFunc.
+type CoerceFunc func(src interface{}, dst interface{}) error
+
+// Coerce implements Coercer
+func (f CoerceFunc) Coerce(src interface{}, dst interface{}) error {
+	return f(src, dst)
+}
+
+// Coercion is the Coercer used by every optional in this package, except Coerced which has
+// its own. It defaults to StrictCoercion. Like UnmarshalMode, it is read without locking, so
+// fields which need another Coercer use Coerced rather than changing it at run time.
+var Coercion Coercer = StrictCoercion{}
+
+var boolText = map[string]bool{
+	"true": true, "yes": true, "on": true, "1": true,
+	"false": false, "no": false, "off": false, "0": false,
+}
+
+// numberText returns text form of numeric src and reports whether src is a number.
+func numberText(src interface{}) (string, bool) {
+	switch v := src.(type) {
+	case json.Number:
+		return v.String(), true
+	case Dec:
+		return v.String(), true
+	}
+	rv := reflect.ValueOf(src)
+	switch k := rv.Kind(); {
+	case isInt(k):
+		return strconv.FormatInt(rv.Int(), 10), true
+	case isUint(k):
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case k == reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), true
+	case k == reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// setNumberText parses decimal number s into numeric rv.
+func setNumberText(s string, rv reflect.Value) error {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return setInt(rv, n)
+	}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		if !isUint(rv.Kind()) {
+			return errOverflow
+		}
+		return setUint(rv, n)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return errKind
+	}
+	return setFloat(rv, f)
+}
+
+// coerceJSON converts JSON b into dst using c.
+func coerceJSON(c Coercer, b []byte, dst interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var src interface{}
+	if err := d.Decode(&src); err != nil {
+		return err
+	}
+	return c.Coerce(src, dst)
+}
+
+// coerceBSON converts value data of BSON element with kind into dst using c.
+func coerceBSON(c Coercer, kind byte, data []byte, dst interface{}) error {
+	src, err := decodeBSONAny(kind, data)
+	if err != nil {
+		return err
+	}
+	return c.Coerce(src, dst)
+}
+
+// Coerced is optional form of T which converts values using zero value of C rather than
+// Coercion, for example:
+//
+//	type Query struct {
+//		Limit goptional.Coerced[int, goptional.LenientCoercion] `json:"limit"`
+//	}
+//
+// accepts both 10 and "10" regardless of Coercion.
+//
+// C has to be usable as its zero value, like StrictCoercion and LenientCoercion. Decoding into
+// Coerced whose C is a function, pointer or interface type, such as CoerceFunc, fails with error.
+//
+// Coerced wraps Of[T] rather than aliasing it since it decodes with its own Coercer.
+type Coerced[T any, C Coercer] struct {
+	Of[T]
+}
+
+// NewCoerced creates a new optional
+func NewCoerced[T any, C Coercer](val T, ok bool) Coerced[T, C] {
+	return Coerced[T, C]{Of[T]{val, ok}}
+}
+
+// UnmarshalJSON is used to unmarshal JSON into optional value, converting it using C
+// if it does not match T.
+// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false)
+// and error is returned only in Strict mode.
+func (o *Coerced[T, C]) UnmarshalJSON(b []byte) error {
+	c, err := coercer[C]()
+	if err != nil {
+		return err
+	}
+	return o.Of.unmarshalJSONWith(b, c)
+}
+
+// SetBSON implements bson.Setter
+func (o *Coerced[T, C]) SetBSON(raw bson.Raw) error {
+	return o.setBSON(raw.Kind, raw.Data)
+}
+
+func (o *Coerced[T, C]) setBSON(kind byte, data []byte) error {
+	c, err := coercer[C]()
+	if err != nil {
+		return err
+	}
+	return o.Of.setBSONWith(kind, data, c)
+}
+
+// Scan implements sql.Scanner. NULL is scanned as invalid optional.
+func (o *Coerced[T, C]) Scan(src interface{}) error {
+	c, err := coercer[C]()
+	if err != nil {
+		return err
+	}
+	return o.Of.scanWith(src, c)
+}
+
+// coercer returns zero value of C, or error if it is nil and would panic when called.
+func coercer[C Coercer]() (Coercer, error) {
+	var c C
+	switch t := reflect.TypeOf(&c).Elem(); t.Kind() {
+	case reflect.Func, reflect.Ptr, reflect.Interface:
+		return nil, fmt.Errorf("Unable to coerce using zero value of %v, which is nil", t)
+	}
+	return c, nil
+}
@@ -0,0 +1,185 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestCoercion(t *testing.T) {
+	type ts struct {
+		I optional.Int     `json:"i" bson:"i"`
+		U optional.Uint8   `json:"u" bson:"u"`
+		F optional.Float64 `json:"f" bson:"f"`
+		S optional.String  `json:"s" bson:"s"`
+		B optional.Bool    `json:"b" bson:"b"`
+	}
+	defer func(c optional.Coercer) { optional.Coercion = c }(optional.Coercion)
+	defer func(m optional.Mode) { optional.UnmarshalMode = m }(optional.UnmarshalMode)
+	optional.UnmarshalMode = optional.Strict
+
+	// Scenario: Values of other types are converted depending on Coercion
+	// Given: JSON message and BSON document with mismatching values
+	testCase := []struct {
+		message  []byte
+		doc      bson.M
+		coercion optional.Coercer
+		expected ts
+		err      bool
+	}{
+		// When: Coercion is strict
+		// Then: Mismatching values fail to decode
+		{[]byte(`{"i":"42"}`), bson.M{"i": "42"}, optional.StrictCoercion{}, ts{}, true},
+		{[]byte(`{"s":42}`), bson.M{"s": 42}, optional.StrictCoercion{}, ts{}, true},
+		{[]byte(`{"b":"yes"}`), bson.M{"b": "yes"}, optional.StrictCoercion{}, ts{}, true},
+		// When: Coercion is lenient
+		// Then: Quoted numbers, numbers into string and textual bools are converted
+		{[]byte(`{"i":"42","u":" 7 ","f":"1.5"}`), bson.M{"i": "42", "u": " 7 ", "f": "1.5"}, optional.LenientCoercion{},
+			ts{I: optional.NewInt(42, true), U: optional.NewUint8(7, true), F: optional.NewFloat64(1.5, true)}, false},
+		{[]byte(`{"s":42,"b":"Yes"}`), bson.M{"s": 42, "b": "Yes"}, optional.LenientCoercion{},
+			ts{S: optional.NewString("42", true), B: optional.NewBool(true, true)}, false},
+		{[]byte(`{"s":1.25,"b":"off"}`), bson.M{"s": 1.25, "b": "off"}, optional.LenientCoercion{},
+			ts{S: optional.NewString("1.25", true), B: optional.NewBool(false, true)}, false},
+		// When: Coercion is lenient but values do not fit
+		// Then: Values still fail to decode
+		{[]byte(`{"u":"300"}`), bson.M{"u": "300"}, optional.LenientCoercion{}, ts{}, true},
+		{[]byte(`{"i":"1.5"}`), bson.M{"i": "1.5"}, optional.LenientCoercion{}, ts{}, true},
+		{[]byte(`{"i":"abc"}`), bson.M{"i": "abc"}, optional.LenientCoercion{}, ts{}, true},
+		{[]byte(`{"b":"maybe"}`), bson.M{"b": "maybe"}, optional.LenientCoercion{}, ts{}, true},
+		// When: Coercion is custom
+		// Then: Values are converted by the function
+		{[]byte(`{"i":"many"}`), bson.M{"i": "many"}, optional.CoerceFunc(func(src, dst interface{}) error {
+			if p, ok := dst.(*int); ok && src == "many" {
+				*p = 100
+				return nil
+			}
+			return optional.LenientCoercion{}.Coerce(src, dst)
+		}), ts{I: optional.NewInt(100, true)}, false},
+	}
+
+	for _, v := range testCase {
+		optional.Coercion = v.coercion
+
+		// When: Unmarshaled from JSON
+		k := ts{}
+		var uerr *optional.UnmarshalError
+		if err := json.Unmarshal(v.message, &k); errors.As(err, &uerr) != v.err {
+			t.Errorf("[optional] Unexpected error from message: %s, got: %v", v.message, err)
+		}
+		if !v.err && k != v.expected {
+			t.Errorf("[optional] Unexpected result from message: %s, expected: %+v, got: %+v", v.message, v.expected, k)
+		}
+
+		// When: Unmarshaled from BSON
+		msg, err := bson.Marshal(v.doc)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %v, error: %v", v.doc, err)
+			continue
+		}
+		k = ts{}
+		if err := bson.Unmarshal(msg, &k); (err != nil) != v.err {
+			t.Errorf("[optional] Unexpected error from document: %v, got: %v", v.doc, err)
+		}
+		if !v.err && k != v.expected {
+			t.Errorf("[optional] Unexpected result from document: %v, expected: %+v, got: %+v", v.doc, v.expected, k)
+		}
+	}
+
+	// Scenario: SQL values are converted depending on Coercion
+	// Given: Textual bool
+	// When: Scanned with strict and lenient Coercion
+	// Then: Only lenient Coercion converts it
+	var b optional.Bool
+	optional.Coercion = optional.StrictCoercion{}
+	if err := b.Scan([]byte("on")); err == nil {
+		t.Errorf("[optional] Expected error from scanning on in strict coercion")
+	}
+	optional.Coercion = optional.LenientCoercion{}
+	if err := b.Scan([]byte("on")); err != nil || b != optional.NewBool(true, true) {
+		t.Errorf("[optional] Unexpected result of scanning on: %v, error: %v", b, err)
+	}
+	var f optional.Float32
+	if err := f.Scan(" 2.5 "); err != nil || f != optional.NewFloat32(2.5, true) {
+		t.Errorf("[optional] Unexpected result of scanning 2.5: %v, error: %v", f, err)
+	}
+}
+
+func TestCoerced(t *testing.T) {
+	type ts struct {
+		L optional.Coerced[int, optional.LenientCoercion] `json:"l" bson:"l"`
+		S optional.Coerced[int, optional.StrictCoercion]  `json:"s" bson:"s"`
+	}
+	defer func(c optional.Coercer) { optional.Coercion = c }(optional.Coercion)
+
+	// Scenario: Field decodes with its own Coercer regardless of Coercion
+	// Given: Quoted numbers
+	testCase := []optional.Coercer{optional.StrictCoercion{}, optional.LenientCoercion{}}
+
+	for _, c := range testCase {
+		optional.Coercion = c
+
+		// When: Unmarshaled from JSON and BSON
+		// Then: Only lenient field is valid, and BSON reports the strict one
+		var k ts
+		if err := json.Unmarshal([]byte(`{"l":"5","s":"5"}`), &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal JSON with %T, error: %v", c, err)
+		}
+		if v, ok := k.L.Get(); !ok || v != 5 || k.S.Ok() {
+			t.Errorf("[optional] Unexpected JSON result with %T: %+v", c, k)
+		}
+		msg, _ := bson.Marshal(bson.D{{Name: "l", Value: "5"}, {Name: "s", Value: "5"}})
+		k = ts{}
+		if err := bson.Unmarshal(msg, &k); err == nil {
+			t.Errorf("[optional] Expected BSON error of strict field with %T", c)
+		}
+		if v, ok := k.L.Get(); !ok || v != 5 || k.S.Ok() {
+			t.Errorf("[optional] Unexpected BSON result with %T: %+v", c, k)
+		}
+
+		// When: Scanned
+		// Then: Only lenient field is valid
+		if err := k.L.Scan("6"); err != nil || k.L != optional.NewCoerced[int, optional.LenientCoercion](6, true) {
+			t.Errorf("[optional] Unexpected scan result with %T: %+v, error: %v", c, k.L, err)
+		}
+		if err := k.S.Scan(" 6"); err == nil {
+			t.Errorf("[optional] Expected error from scanning with %T", c)
+		}
+	}
+
+	// Scenario: Coerced is marshaled as its value
+	// Given: Valid optional
+	// When: Marshaled into JSON
+	// Then: Value is marshaled
+	b, err := json.Marshal(optional.NewCoerced[int, optional.LenientCoercion](7, true))
+	if err != nil || string(b) != "7" {
+		t.Errorf("[optional] Unexpected JSON: %s, error: %v", b, err)
+	}
+
+	// Scenario: Coerced whose Coercer is nil reports error instead of panicking
+	// Given: Coerced of CoerceFunc and pointer Coercer
+	type tn struct {
+		F optional.Coerced[int, optional.CoerceFunc]       `json:"f" bson:"f"`
+		P optional.Coerced[int, *optional.LenientCoercion] `json:"p" bson:"p"`
+	}
+	msg, _ := bson.Marshal(bson.D{{Name: "f", Value: "5"}})
+	var n tn
+
+	// When: Unmarshaled from JSON and BSON, and scanned
+	// Then: Returns error
+	if err := json.Unmarshal([]byte(`{"f":"5"}`), &n); err == nil {
+		t.Errorf("[optional] Expected JSON error of nil CoerceFunc")
+	}
+	if err := json.Unmarshal([]byte(`{"p":5}`), &n); err == nil {
+		t.Errorf("[optional] Expected JSON error of nil pointer Coercer")
+	}
+	if err := bson.Unmarshal(msg, &n); err == nil {
+		t.Errorf("[optional] Expected BSON error of nil CoerceFunc")
+	}
+	if err := n.F.Scan("5"); err == nil {
+		t.Errorf("[optional] Expected scan error of nil CoerceFunc")
+	}
+}
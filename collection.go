@@ -0,0 +1,23 @@
+package goptional
+
+// SliceOf is nullable form of []T which tells apart a slice that is absent, explicitly null
+// or present, even if empty. Present nil slice is marshaled as empty one. T may itself be
+// an optional, such as in SliceOf[Int]. As with Nullable, tag it with `bson:",omitempty"`
+// to keep absent slice apart from null one through gopkg.in/mgo.v2/bson.
+type SliceOf[T any] = Nullable[[]T]
+
+// NewSliceOf creates a new slice which is present if ok is true and absent otherwise.
+func NewSliceOf[T any](val []T, ok bool) SliceOf[T] {
+	return NewNullable(val, ok)
+}
+
+// MapOf is nullable form of map[K]V which tells apart a map that is absent, explicitly null
+// or present, even if empty. Present nil map is marshaled as empty one. V may itself be
+// an optional, such as in MapOf[string, Int]. As with Nullable, tag it with `bson:",omitempty"`
+// to keep absent map apart from null one through gopkg.in/mgo.v2/bson.
+type MapOf[K comparable, V any] = Nullable[map[K]V]
+
+// NewMapOf creates a new map which is present if ok is true and absent otherwise.
+func NewMapOf[K comparable, V any](val map[K]V, ok bool) MapOf[K, V] {
+	return NewNullable(val, ok)
+}
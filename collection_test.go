@@ -0,0 +1,105 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestCollection(t *testing.T) {
+	type ts struct {
+		S optional.SliceOf[optional.Int]          `json:"s,omitzero" bson:"s,omitempty"`
+		M optional.MapOf[string, optional.String] `json:"m,omitzero" bson:"m,omitempty"`
+	}
+
+	// Scenario: Absent, null and empty collections are told apart
+	// Given: JSON message with absent, null, empty or filled collections
+	testCase := []struct {
+		message  []byte
+		expected ts
+	}{
+		{[]byte(`{}`), ts{}},
+		{[]byte(`{"s":null,"m":null}`), ts{optional.Null[[]optional.Int](), optional.Null[map[string]optional.String]()}},
+		{
+			[]byte(`{"s":[],"m":{}}`),
+			ts{optional.NewSliceOf([]optional.Int{}, true), optional.NewMapOf(map[string]optional.String{}, true)},
+		},
+		{
+			[]byte(`{"s":[1,null],"m":{"x":"y","z":null}}`),
+			ts{
+				optional.NewSliceOf([]optional.Int{optional.NewInt(1, true), {}}, true),
+				optional.NewMapOf(map[string]optional.String{"x": optional.NewString("y", true), "z": {}}, true),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		// When: Unmarshaled and marshaled again
+		// Then: Collection state is kept
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if !reflect.DeepEqual(k, v.expected) {
+			t.Errorf("[optional] Unexpected unmarshal result, expected: %v, got: %v", v.expected, k)
+		}
+		msg, err := json.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		if string(msg) != string(v.message) {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.message, msg)
+		}
+
+		// When: Marshaled using MarshalBSON and unmarshaled using mgo and UnmarshalBSON
+		// Then: Collection state is kept
+		msg, err = optional.MarshalBSON(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		u, nu := ts{}, ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if err := optional.UnmarshalBSON(msg, &nu); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if !reflect.DeepEqual(u, v.expected) || !reflect.DeepEqual(nu, v.expected) {
+			t.Errorf("[optional] Unexpected BSON unmarshal result, expected: %v, got: %v and %v", v.expected, u, nu)
+		}
+
+		// When: Marshaled and unmarshaled using mgo
+		// Then: Collection state is kept
+		msg, err = bson.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		u = ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if !reflect.DeepEqual(u, v.expected) {
+			t.Errorf("[optional] Unexpected mgo round trip result, expected: %v, got: %v", v.expected, u)
+		}
+	}
+
+	// Scenario: Present nil collection is not null
+	// Given: Present slice and map holding nil
+	// When: Marshaled into JSON
+	// Then: Collections are empty
+	msg, _ := json.Marshal(ts{optional.NewSliceOf[optional.Int](nil, true), optional.NewMapOf[string, optional.String](nil, true)})
+	if expected := `{"s":[],"m":{}}`; string(msg) != expected {
+		t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", expected, msg)
+	}
+}
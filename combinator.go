@@ -0,0 +1,79 @@
+package goptional
+
+import "fmt"
+
+// Getter is implemented by optionals of T and pointers to them, such as Of[T],
+// Nullable[T] and Bool, so that the functions below work across all of them.
+type Getter[T any] interface {
+	Get() (T, bool)
+}
+
+// Map returns optional of f applied to value of o, or invalid optional if o is invalid.
+func Map[T, U any](o Getter[T], f func(T) U) Of[U] {
+	if v, ok := o.Get(); ok {
+		return Of[U]{f(v), true}
+	}
+	return Of[U]{}
+}
+
+// FlatMap returns optional returned by f applied to value of o, or invalid optional
+// if o is invalid.
+func FlatMap[T, U any](o Getter[T], f func(T) Of[U]) Of[U] {
+	if v, ok := o.Get(); ok {
+		return f(v)
+	}
+	return Of[U]{}
+}
+
+// AndThen is FlatMap.
+func AndThen[T, U any](o Getter[T], f func(T) Of[U]) Of[U] {
+	return FlatMap(o, f)
+}
+
+// Filter returns o if it is valid and its value satisfies pred, or invalid optional otherwise.
+func Filter[T any](o Getter[T], pred func(T) bool) Of[T] {
+	if v, ok := o.Get(); ok && pred(v) {
+		return Of[T]{v, true}
+	}
+	return Of[T]{}
+}
+
+// Or returns o if it is valid, or other otherwise.
+func Or[T any](o Getter[T], other Of[T]) Of[T] {
+	if v, ok := o.Get(); ok {
+		return Of[T]{v, true}
+	}
+	return other
+}
+
+// OrElse returns value of o if it is valid, or def otherwise.
+func OrElse[T any](o Getter[T], def T) T {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return def
+}
+
+// OrElseGet returns value of o if it is valid, or result of f otherwise.
+// f is called only if o is invalid.
+func OrElseGet[T any](o Getter[T], f func() T) T {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return f()
+}
+
+// OrZero returns value of o if it is valid, or zero value of T otherwise.
+func OrZero[T any](o Getter[T]) T {
+	var zero T
+	return OrElse(o, zero)
+}
+
+// MustGet returns value of o and panics if o is invalid.
+func MustGet[T any](o Getter[T]) T {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	var zero T
+	panic(fmt.Sprintf("goptional: MustGet called on invalid optional of %T", zero))
+}
@@ -0,0 +1,85 @@
+package optional_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestCombinator(t *testing.T) {
+	valid, invalid := optional.NewInt(42, true), optional.Int{}
+	itoa := func(v int) string { return strconv.Itoa(v) }
+	half := func(v int) optional.Int {
+		if v%2 != 0 {
+			return optional.Int{}
+		}
+		return optional.NewInt(v/2, true)
+	}
+	even := func(v int) bool { return v%2 == 0 }
+	odd := optional.AndThen(&valid, half)
+
+	// Scenario: Combinators transform valid optional and skip invalid one
+	// Given: Valid and invalid optionals
+	testCase := []struct {
+		name     string
+		got      interface{}
+		expected interface{}
+	}{
+		// When: Value is transformed
+		// Then: Result is valid only if input is
+		{"Map", optional.Map(&valid, itoa), optional.NewString("42", true)},
+		{"Map", optional.Map(&invalid, itoa), optional.String{}},
+		{"FlatMap", optional.FlatMap(&valid, half), optional.NewInt(21, true)},
+		{"AndThen", optional.AndThen(&invalid, half), optional.Int{}},
+		{"AndThen", optional.AndThen(&odd, half), optional.Int{}},
+		{"Filter", optional.Filter(&valid, even), valid},
+		{"Filter", optional.Filter(&invalid, even), invalid},
+		{"Filter", optional.Filter(&valid, func(v int) bool { return v > 50 }), invalid},
+		// When: Value is extracted
+		// Then: Fallback is used only for invalid optional
+		{"Or", optional.Or(&valid, optional.NewInt(1, true)), valid},
+		{"Or", optional.Or(&invalid, optional.NewInt(1, true)), optional.NewInt(1, true)},
+		{"OrElse", optional.OrElse(&valid, 7), 42},
+		{"OrElse", optional.OrElse(&invalid, 7), 7},
+		{"OrElseGet", optional.OrElseGet(&invalid, func() int { return 8 }), 8},
+		{"OrZero", optional.OrZero(&invalid), 0},
+		{"MustGet", optional.MustGet(&valid), 42},
+	}
+
+	for _, v := range testCase {
+		if v.got != v.expected {
+			t.Errorf("[optional] Unexpected result of %s, expected: %v, got: %v", v.name, v.expected, v.got)
+		}
+	}
+
+	// Scenario: Combinators work across optional types
+	// Given: Bool and nullable optionals
+	// When: Value is transformed
+	// Then: Result follows validity of input
+	b := optional.NewBool(true, true)
+	if s := optional.Map(&b, strconv.FormatBool); s != optional.NewString("true", true) {
+		t.Errorf("[optional] Unexpected result of Map on Bool: %v", s)
+	}
+	n := optional.Null[string]()
+	if s := optional.OrElse(&n, "default"); s != "default" {
+		t.Errorf("[optional] Unexpected result of OrElse on Nullable: %v", s)
+	}
+	called := false
+	optional.OrElseGet(&valid, func() int { called = true; return 0 })
+	if called {
+		t.Errorf("[optional] OrElseGet called function for valid optional")
+	}
+
+	// Scenario: MustGet panics on invalid optional
+	// Given: Invalid optional
+	// When: MustGet is called
+	// Then: Panics naming the type
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "int") {
+			t.Errorf("[optional] Unexpected panic: %v", r)
+		}
+	}()
+	optional.MustGet(&invalid)
+}
@@ -0,0 +1,161 @@
+package goptional
+
+import (
+	"database/sql"
+	"math/big"
+	"time"
+)
+
+// Functions below convert optionals from and into pointers and sql.Null types. Optionals
+// wrapping Of[T], such as Enum, Bytes or Date, are created by passing value-flag pair of the
+// result to their constructor, which checks or copies the value as the wrapper requires, e.g.
+// NewEnum[Status, Statuses](FromPtr(p).Get()) or NewDate(FromNullTime(n).Get()).
+// Ptr and the functions taking Getter accept every optional, including Nullable.
+
+// FromPtr creates optional of value pointed by p, which is invalid if p is nil.
+func FromPtr[T any](p *T) Of[T] {
+	if p == nil {
+		return Of[T]{}
+	}
+	return Of[T]{*p, true}
+}
+
+// Ptr returns pointer to copy of value of o, or nil if o is invalid.
+func Ptr[T any](o Getter[T]) *T {
+	if v, ok := o.Get(); ok {
+		return &v
+	}
+	return nil
+}
+
+// FromZero creates optional of v, which is invalid if v is zero value of T or reports itself
+// as zero through IsZero method, as time.Time and Dec do. *big.Int is zero when it is nil or 0.
+func FromZero[T comparable](v T) Of[T] {
+	var zero T
+	if v == zero {
+		return Of[T]{v, false}
+	}
+	switch z := interface{}(v).(type) {
+	case *big.Int:
+		return Of[T]{v, z.Sign() != 0}
+	case interface{ IsZero() bool }:
+		return Of[T]{v, !z.IsZero()}
+	}
+	return Of[T]{v, true}
+}
+
+// NullableFromPtr creates nullable of value pointed by p, which is null if p is nil.
+// It creates SliceOf and MapOf as well.
+func NullableFromPtr[T any](p *T) Nullable[T] {
+	if p == nil {
+		return Null[T]()
+	}
+	return NewNullable(*p, true)
+}
+
+// NullableFromNull creates nullable from n, which is null if n is not valid.
+func NullableFromNull[T any](n sql.Null[T]) Nullable[T] {
+	if !n.Valid {
+		return Null[T]()
+	}
+	return NewNullable(n.V, true)
+}
+
+// FromNull creates optional from n, which is invalid if n is not valid.
+func FromNull[T any](n sql.Null[T]) Of[T] {
+	return Of[T]{n.V, n.Valid}
+}
+
+// ToNull converts o into sql.Null[T].
+func ToNull[T any](o Getter[T]) sql.Null[T] {
+	v, ok := o.Get()
+	return sql.Null[T]{V: v, Valid: ok}
+}
+
+// FromNullInt64 creates optional from n, which is invalid if n is not valid.
+func FromNullInt64(n sql.NullInt64) Int64 {
+	return Int64{n.Int64, n.Valid}
+}
+
+// ToNullInt64 converts o into sql.NullInt64.
+func ToNullInt64(o Getter[int64]) sql.NullInt64 {
+	v, ok := o.Get()
+	return sql.NullInt64{Int64: v, Valid: ok}
+}
+
+// FromNullInt32 creates optional from n, which is invalid if n is not valid.
+func FromNullInt32(n sql.NullInt32) Int32 {
+	return Int32{n.Int32, n.Valid}
+}
+
+// ToNullInt32 converts o into sql.NullInt32.
+func ToNullInt32(o Getter[int32]) sql.NullInt32 {
+	v, ok := o.Get()
+	return sql.NullInt32{Int32: v, Valid: ok}
+}
+
+// FromNullInt16 creates optional from n, which is invalid if n is not valid.
+func FromNullInt16(n sql.NullInt16) Int16 {
+	return Int16{n.Int16, n.Valid}
+}
+
+// ToNullInt16 converts o into sql.NullInt16.
+func ToNullInt16(o Getter[int16]) sql.NullInt16 {
+	v, ok := o.Get()
+	return sql.NullInt16{Int16: v, Valid: ok}
+}
+
+// FromNullByte creates optional from n, which is invalid if n is not valid.
+func FromNullByte(n sql.NullByte) Uint8 {
+	return Uint8{n.Byte, n.Valid}
+}
+
+// ToNullByte converts o into sql.NullByte.
+func ToNullByte(o Getter[uint8]) sql.NullByte {
+	v, ok := o.Get()
+	return sql.NullByte{Byte: v, Valid: ok}
+}
+
+// FromNullFloat64 creates optional from n, which is invalid if n is not valid.
+func FromNullFloat64(n sql.NullFloat64) Float64 {
+	return Float64{n.Float64, n.Valid}
+}
+
+// ToNullFloat64 converts o into sql.NullFloat64.
+func ToNullFloat64(o Getter[float64]) sql.NullFloat64 {
+	v, ok := o.Get()
+	return sql.NullFloat64{Float64: v, Valid: ok}
+}
+
+// FromNullString creates optional from n, which is invalid if n is not valid.
+func FromNullString(n sql.NullString) String {
+	return String{n.String, n.Valid}
+}
+
+// ToNullString converts o into sql.NullString.
+func ToNullString(o Getter[string]) sql.NullString {
+	v, ok := o.Get()
+	return sql.NullString{String: v, Valid: ok}
+}
+
+// FromNullBool creates optional from n, which is invalid if n is not valid.
+func FromNullBool(n sql.NullBool) Bool {
+	return NewBool(n.Bool, n.Valid)
+}
+
+// ToNullBool converts o into sql.NullBool.
+func ToNullBool(o Getter[bool]) sql.NullBool {
+	v, ok := o.Get()
+	return sql.NullBool{Bool: v, Valid: ok}
+}
+
+// FromNullTime creates optional from n, which is invalid if n is not valid.
+func FromNullTime(n sql.NullTime) Time {
+	return Time{n.Time, n.Valid}
+}
+
+// ToNullTime converts o into sql.NullTime.
+func ToNullTime(o Getter[time.Time]) sql.NullTime {
+	v, ok := o.Get()
+	return sql.NullTime{Time: v, Valid: ok}
+}
@@ -0,0 +1,88 @@
+package optional_test
+
+import (
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestConvert(t *testing.T) {
+	n, s := int64(5), "s"
+	now := time.Unix(1500000000, 0)
+	valid, invalid := optional.NewInt64(5, true), optional.Int64{}
+	deleted := status("deleted")
+	zeroDec, _ := optional.ParseDec("0.00")
+	oneDec, _ := optional.ParseDec("0.01")
+
+	// Scenario: Optionals are converted from and into pointers, zero values and sql.Null types
+	// Given: Valid and invalid counterparts
+	// When: Converted
+	// Then: Validity and value are kept
+	testCase := []struct {
+		name     string
+		got      interface{}
+		expected interface{}
+	}{
+		{"FromPtr", optional.FromPtr(&n), valid},
+		{"FromPtr", optional.FromPtr[int64](nil), invalid},
+		{"FromPtr", optional.NewBool(optional.FromPtr[bool](nil).Get()), optional.Bool{}},
+		{"FromPtr", optional.NewEnum[status, statuses](optional.FromPtr(&deleted).Get()).IsUnknown(), true},
+		{"Ptr", *optional.Ptr(&valid), n},
+		{"Ptr", optional.Ptr(&invalid), (*int64)(nil)},
+		{"FromZero", optional.FromZero(s), optional.NewString("s", true)},
+		{"FromZero", optional.FromZero(""), optional.String{}},
+		{"FromZero", optional.FromZero(0.0), optional.Float64{}},
+		{"FromZero", optional.FromZero(zeroDec).Ok(), false},
+		{"FromZero", optional.FromZero(oneDec).Ok(), true},
+		{"FromZero", optional.FromZero(big.NewInt(0)).Ok(), false},
+		{"FromZero", optional.FromZero((*big.Int)(nil)).Ok(), false},
+		{"FromZero", optional.FromZero(big.NewInt(1)).Ok(), true},
+		{"FromZero", optional.FromZero(time.Time{}.In(time.FixedZone("WIB", 7*3600))).Ok(), false},
+		{"FromZero", optional.FromZero(now).Ok(), true},
+		{"FromNull", optional.FromNull(sql.Null[int64]{V: 5, Valid: true}), valid},
+		{"ToNull", optional.ToNull(&invalid), sql.Null[int64]{}},
+		{"FromNullInt64", optional.FromNullInt64(sql.NullInt64{Int64: 5, Valid: true}), valid},
+		{"ToNullInt64", optional.ToNullInt64(&valid), sql.NullInt64{Int64: 5, Valid: true}},
+		{"FromNullString", optional.FromNullString(sql.NullString{}), optional.String{}},
+		{"FromNullFloat64", optional.FromNullFloat64(sql.NullFloat64{Float64: 1.5, Valid: true}), optional.NewFloat64(1.5, true)},
+		{"FromNullBool", optional.FromNullBool(sql.NullBool{Bool: true, Valid: true}), optional.NewBool(true, true)},
+		{"FromNullTime", optional.FromNullTime(sql.NullTime{Time: now, Valid: true}), optional.NewTime(now, true)},
+		{"FromNullByte", optional.FromNullByte(sql.NullByte{Byte: 7, Valid: true}), optional.NewUint8(7, true)},
+		{"NullableFromPtr", optional.NullableFromPtr(&n), optional.NewNullable(n, true)},
+		{"NullableFromPtr", optional.NullableFromPtr[int64](nil), optional.Null[int64]()},
+		{"NullableFromNull", optional.NullableFromNull(sql.Null[string]{V: "s", Valid: true}), optional.NewNullable("s", true)},
+		{"NullableFromNull", optional.NullableFromNull(sql.Null[string]{}), optional.Null[string]()},
+	}
+
+	for _, v := range testCase {
+		if v.got != v.expected {
+			t.Errorf("[optional] Unexpected result of %s, expected: %v, got: %v", v.name, v.expected, v.got)
+		}
+	}
+
+	// Scenario: Pointer does not alias optional
+	// Given: Pointer returned by Ptr
+	// When: Value is modified through pointer
+	// Then: Optional is unchanged
+	p := optional.Ptr(&valid)
+	*p = 9
+	if v, _ := valid.Get(); v != 5 {
+		t.Errorf("[optional] Unexpected aliasing, got: %v", v)
+	}
+
+	// Scenario: Wrapper optionals are converted
+	// Given: Valid Bool and Date
+	// When: Converted into sql.Null types
+	// Then: Validity and value are kept
+	b := optional.NewBool(true, true)
+	d := optional.NewDate(now, true)
+	if nb := optional.ToNullBool(&b); nb != (sql.NullBool{Bool: true, Valid: true}) {
+		t.Errorf("[optional] Unexpected result of ToNullBool: %v", nb)
+	}
+	if nt := optional.ToNullTime(&d); nt != (sql.NullTime{Time: now, Valid: true}) {
+		t.Errorf("[optional] Unexpected result of ToNullTime: %v", nt)
+	}
+}
@@ -0,0 +1,302 @@
+package goptional
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// maxDecScale limits exponent of parsed decimal so that a short input can not
+// allocate huge number.
+const maxDecScale = 1 << 15
+
+var (
+	errDecSyntax = errors.New("invalid decimal syntax")
+	errDecRange  = errors.New("decimal exponent out of range")
+)
+
+// Dec is an arbitrary-precision decimal number whose value is coefficient × 10^-scale.
+// Its zero value is 0. Trailing zeros are kept, so that 1.50 is marshaled back as 1.50.
+type Dec struct {
+	coef  *big.Int
+	scale int32
+}
+
+// NewDec creates decimal of coef × 10^-scale. Negative scale is normalized to 0.
+func NewDec(coef *big.Int, scale int32) Dec {
+	c := new(big.Int)
+	if coef != nil {
+		c.Set(coef)
+	}
+	if scale < 0 {
+		c.Mul(c, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	return Dec{c, scale}
+}
+
+// ParseDec parses decimal in plain or exponent notation, such as "-12.50" or "1.2e-3".
+func ParseDec(s string) (Dec, error) {
+	m, e := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Dec{}, fmt.Errorf("Unable to parse decimal %q: %v", s, errDecSyntax)
+		}
+		m, e = s[:i], n
+	}
+	neg := strings.HasPrefix(m, "-")
+	if neg || strings.HasPrefix(m, "+") {
+		m = m[1:]
+	}
+	intPart, frac, _ := strings.Cut(m, ".")
+	digits := intPart + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Dec{}, fmt.Errorf("Unable to parse decimal %q: %v", s, errDecSyntax)
+	}
+	scale := len(frac) - e
+	if scale > maxDecScale || scale < -maxDecScale {
+		return Dec{}, fmt.Errorf("Unable to parse decimal %q: %v", s, errDecRange)
+	}
+	c, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		c.Neg(c)
+	}
+	return NewDec(c, int32(scale)), nil
+}
+
+// Coef returns coefficient of the decimal.
+func (d Dec) Coef() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.coef)
+}
+
+// Scale returns number of digits after decimal point.
+func (d Dec) Scale() int32 {
+	return d.scale
+}
+
+// Rat returns the decimal as rational number.
+func (d Dec) Rat() *big.Rat {
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.Coef(), den)
+}
+
+// Cmp compares d and e numerically, ignoring trailing zeros.
+func (d Dec) Cmp(e Dec) int {
+	return d.Rat().Cmp(e.Rat())
+}
+
+// IsZero reports whether d equals 0, regardless of its scale.
+func (d Dec) IsZero() bool {
+	return d.coef == nil || d.coef.Sign() == 0
+}
+
+// String formats the decimal in plain notation, without exponent.
+func (d Dec) String() string {
+	c := d.Coef()
+	s := c.Abs(c).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(s); n > 0 {
+			s = strings.Repeat("0", n) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.coef != nil && d.coef.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// UnmarshalJSON parses JSON number or numeric string exactly.
+func (d *Dec) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseDec(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON marshals the decimal as JSON number in plain notation.
+func (d Dec) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Scan implements sql.Scanner. NUMERIC is scanned from its text or from integer and float.
+func (d *Dec) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Errorf("Unable to scan %T into decimal", src)
+	}
+	v, err := ParseDec(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements driver.Valuer. Decimal is stored as text, which is accepted for NUMERIC.
+func (d Dec) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// SetBSON implements bson.Setter
+func (d *Dec) SetBSON(raw bson.Raw) error {
+	return d.setBSON(raw.Kind, raw.Data)
+}
+
+// setBSON decodes decimal128, string, and numbers. Double is decoded as its shortest representation.
+func (d *Dec) setBSON(kind byte, data []byte) error {
+	if kind == 0x0A || kind == 0x06 {
+		*d = Dec{}
+		return nil
+	}
+	s, err := bsonNumberText(kind, data)
+	if err == nil {
+		*d, err = ParseDec(s)
+	}
+	if err != nil {
+		return bsonError(kind, reflect.TypeOf(d).Elem(), err)
+	}
+	return nil
+}
+
+// bsonNumberText returns text of BSON decimal128, string, or number. Double is formatted
+// as its shortest representation.
+func bsonNumberText(kind byte, data []byte) (s string, err error) {
+	switch kind {
+	case 0x13:
+		var x decimal128
+		if x, err = readDecimal128(data); err == nil {
+			s = x.String()
+		}
+	case 0x02:
+		s, err = readBSONString(data)
+	case 0x10:
+		if len(data) < 4 {
+			err = errCorrupt
+		} else {
+			s = strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(data))), 10)
+		}
+	case 0x12:
+		if len(data) < 8 {
+			err = errCorrupt
+		} else {
+			s = strconv.FormatInt(int64(binary.LittleEndian.Uint64(data)), 10)
+		}
+	case 0x01:
+		if len(data) < 8 {
+			err = errCorrupt
+		} else {
+			s = strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)), 'g', -1, 64)
+		}
+	default:
+		err = errKind
+	}
+	return s, err
+}
+
+// GetBSON implements bson.Getter. Decimal is stored as decimal128, which holds up to 34 digits.
+func (d Dec) GetBSON() (interface{}, error) {
+	return decimal128Of(d.Coef(), -int(d.scale))
+}
+
+// decimal128 is IEEE 754 decimal128 number in binary integer encoding, as stored in BSON.
+type decimal128 struct {
+	hi, lo uint64
+}
+
+var maxDecimal128Coef = new(big.Int).Exp(big.NewInt(10), big.NewInt(34), nil)
+
+// decimal128Of encodes coef × 10^exp. Trailing zeros of coefficients longer than 34 digits
+// are moved into exponent, other numbers which do not fit are rejected.
+func decimal128Of(coef *big.Int, exp int) (decimal128, error) {
+	c := new(big.Int).Abs(coef)
+	ten, r := big.NewInt(10), new(big.Int)
+	for c.Cmp(maxDecimal128Coef) >= 0 {
+		q, _ := new(big.Int).QuoRem(c, ten, r)
+		if r.Sign() != 0 {
+			return decimal128{}, fmt.Errorf("Unable to store %v digits in decimal128: %v", len(c.String()), errOverflow)
+		}
+		c, exp = q, exp+1
+	}
+	if exp < -6176 || exp > 6111 {
+		return decimal128{}, fmt.Errorf("Unable to store exponent %v in decimal128: %v", exp, errDecRange)
+	}
+	x := decimal128{
+		hi: uint64(exp+6176)<<49 | new(big.Int).Rsh(c, 64).Uint64(),
+		lo: new(big.Int).And(c, new(big.Int).SetUint64(math.MaxUint64)).Uint64(),
+	}
+	if coef.Sign() < 0 {
+		x.hi |= 1 << 63
+	}
+	return x, nil
+}
+
+// readDecimal128 reads decimal128 from 16 little endian bytes of BSON element.
+func readDecimal128(data []byte) (decimal128, error) {
+	if len(data) < 16 {
+		return decimal128{}, errCorrupt
+	}
+	return decimal128{hi: binary.LittleEndian.Uint64(data[8:]), lo: binary.LittleEndian.Uint64(data)}, nil
+}
+
+// String returns the number as coefficient and exponent, e.g. 150E-2, or as NaN, Inf and -Inf.
+func (x decimal128) String() string {
+	sign := ""
+	if x.hi>>63 == 1 {
+		sign = "-"
+	}
+	switch {
+	case x.hi>>58&0x1F == 0x1F:
+		return "NaN"
+	case x.hi>>58&0x1F == 0x1E:
+		return sign + "Inf"
+	case x.hi>>61&3 == 3:
+		// Coefficient of this form exceeds 34 digits, so it is read as 0.
+		return fmt.Sprintf("%s0E%d", sign, int(x.hi>>47&0x3FFF)-6176)
+	}
+	c := new(big.Int).SetUint64(x.hi & (1<<49 - 1))
+	c.Lsh(c, 64).Or(c, new(big.Int).SetUint64(x.lo))
+	if c.Cmp(maxDecimal128Coef) >= 0 {
+		c.SetInt64(0)
+	}
+	return fmt.Sprintf("%s%vE%d", sign, c, int(x.hi>>49&0x3FFF)-6176)
+}
+
+// Decimal is optional form of Dec, an arbitrary-precision decimal number.
+// It unmarshals JSON number and numeric string exactly, without going through float64.
+type Decimal = Of[Dec]
+
+// NewDecimal creates a new optional
+func NewDecimal(val Dec, ok bool) Decimal {
+	return Decimal{val, ok}
+}
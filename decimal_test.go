@@ -0,0 +1,123 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestDecimal(t *testing.T) {
+	type ts struct {
+		A optional.Decimal `json:"a" bson:"a"`
+	}
+
+	// Scenario: Unmarshal decimal from JSON then marshal again
+	// Given: JSON message with decimal number or numeric string
+	testCase := []struct {
+		message  []byte
+		ok       bool
+		expected string
+	}{
+		// When: Decimal is valid
+		// Then: It is marshaled back exactly in plain notation
+		{[]byte(`{"a":0.1}`), true, `{"a":0.1}`},
+		{[]byte(`{"a":"1.50"}`), true, `{"a":1.50}`},
+		{[]byte(`{"a":-0.003}`), true, `{"a":-0.003}`},
+		{[]byte(`{"a":1.5e-3}`), true, `{"a":0.0015}`},
+		{[]byte(`{"a":12E2}`), true, `{"a":1200}`},
+		{[]byte(`{"a":123456789012345678901234567890.123456789}`), true, `{"a":123456789012345678901234567890.123456789}`},
+		// When: Decimal is malformed or null
+		// Then: Optional is invalid
+		{[]byte(`{"a":"1.2.3"}`), false, `{"a":null}`},
+		{[]byte(`{"a":"abc"}`), false, `{"a":null}`},
+		{[]byte(`{"a":true}`), false, `{"a":null}`},
+		{[]byte(`{"a":1e99999}`), false, `{"a":null}`},
+		{[]byte(`{"a":null}`), false, `{"a":null}`},
+	}
+
+	for _, v := range testCase {
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if k.A.Ok() != v.ok {
+			t.Errorf("[optional] Unexpected validity from message: %s, got: %v", v.message, k.A.Ok())
+		}
+		msg, err := json.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		if string(msg) != v.expected {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.expected, msg)
+		}
+	}
+
+	// Scenario: Decimal arithmetic is exact
+	// Given: 0.1 and 0.2 as decimals
+	// When: Added as rational numbers
+	// Then: Sum equals 0.3
+	a, _ := optional.ParseDec("0.1")
+	b, _ := optional.ParseDec("0.2")
+	c, _ := optional.ParseDec("0.30")
+	if a.Rat().Add(a.Rat(), b.Rat()).Cmp(c.Rat()) != 0 || c.Cmp(c) != 0 {
+		t.Errorf("[optional] Unexpected inexact decimal sum")
+	}
+
+	// Scenario: Decimal is stored as BSON decimal128
+	// Given: Decimal optional
+	// When: Marshaled using MarshalBSON and mgo, then unmarshaled
+	// Then: Bytes are the same and result matches original data
+	d, _ := optional.ParseDec("-1234.50")
+	k := ts{optional.NewDecimal(d, true)}
+	msg, err := optional.MarshalBSON(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", k, err)
+	}
+	expected, err := bson.Marshal(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v with mgo, error: %v", k, err)
+	}
+	if !bytes.Equal(msg, expected) {
+		t.Errorf("[optional] Unexpected BSON message, expected: %x, got: %x", expected, msg)
+	}
+	for _, doc := range []interface{}{k, bson.M{"a": "-1234.50"}, bson.M{"a": -1234.5}} {
+		msg, _ := bson.Marshal(doc)
+		u, nu := ts{}, ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal %v, error: %v", doc, err)
+			continue
+		}
+		if err := optional.UnmarshalBSON(msg, &nu); err != nil {
+			t.Errorf("[optional] Fail to unmarshal %v, error: %v", doc, err)
+			continue
+		}
+		if v, ok := u.A.Get(); !ok || v.Cmp(d) != 0 {
+			t.Errorf("[optional] Unexpected unmarshal result of %v, got: %v", doc, v)
+		}
+		if v, ok := nu.A.Get(); !ok || v.Cmp(d) != 0 {
+			t.Errorf("[optional] Unexpected unmarshal result of %v, got: %v", doc, v)
+		}
+	}
+
+	// Scenario: Decimal is stored in SQL as text
+	// Given: Decimal optional
+	// When: Converted to driver value and scanned back
+	// Then: Result matches original data
+	dv, err := k.A.Value()
+	if err != nil || dv != "-1234.50" {
+		t.Errorf("[optional] Unexpected driver value: %v, error: %v", dv, err)
+	}
+	u := ts{}
+	if err := u.A.Scan([]byte("-1234.50")); err != nil {
+		t.Errorf("[optional] Fail to scan decimal, error: %v", err)
+	}
+	if v, ok := u.A.Get(); !ok || v.String() != "-1234.50" {
+		t.Errorf("[optional] Unexpected scan result: %v", v)
+	}
+}
@@ -0,0 +1,59 @@
+package goptional
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is optional form of time.Duration.
+// It unmarshals JSON string using time.ParseDuration and JSON integer as nanoseconds,
+// and marshals into string such as "1m30s". It is stored in BSON and SQL as nanoseconds.
+type Duration = Of[time.Duration]
+
+// NewDuration creates a new optional
+func NewDuration(val time.Duration, ok bool) Duration {
+	return Duration{val, ok}
+}
+
+// unmarshalDuration unmarshals JSON string or integer b into d.
+func unmarshalDuration(b []byte, d *time.Duration) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = v
+		return nil
+	}
+	return json.Unmarshal(b, (*int64)(d))
+}
+
+// scanDuration converts SQL text into d, either duration string or integer nanoseconds,
+// which drivers of text protocol return for integer columns. Other values are not handled,
+// reported by ok.
+func scanDuration(src interface{}, d *time.Duration) (ok bool, err error) {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return false, nil
+	}
+	if *d, err = time.ParseDuration(s); err == nil {
+		return true, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return true, fmt.Errorf("Unable to parse duration %q", s)
+	}
+	*d = time.Duration(n)
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestDuration(t *testing.T) {
+	type ts struct {
+		A optional.Duration `json:"a"`
+	}
+
+	// Scenario: Unmarshal duration from JSON
+	// Given: JSON message with duration string or nanoseconds
+	testCase := []struct {
+		message []byte
+		ok      bool
+		a       time.Duration
+	}{
+		// When: Duration is parsable
+		// Then: Optional is valid
+		{[]byte(`{"a": "30s"}`), true, 30 * time.Second},
+		{[]byte(`{"a": "1h2m3.5s"}`), true, time.Hour + 2*time.Minute + 3500*time.Millisecond},
+		{[]byte(`{"a": 1500}`), true, 1500},
+		{[]byte(`{"a": "0s"}`), true, 0},
+		// When: Duration is malformed or null
+		// Then: Optional is invalid
+		{[]byte(`{"a": "30 seconds"}`), false, 0},
+		{[]byte(`{"a": 1.5}`), false, 0},
+		{[]byte(`{"a": null}`), false, 0},
+	}
+
+	for _, v := range testCase {
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if a, ok := k.A.Get(); ok != v.ok || a != v.a && ok {
+			t.Errorf("[optional] Unexpected duration from message: %s, got: %v %v", v.message, a, ok)
+		}
+	}
+
+	// Scenario: Duration is marshaled as string
+	// Given: Valid duration optional
+	// When: Marshaled into JSON
+	// Then: JSON holds duration string
+	k := ts{A: optional.NewDuration(90*time.Second, true)}
+	msg, err := json.Marshal(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", k, err)
+	}
+	if expected := `{"a":"1m30s"}`; string(msg) != expected {
+		t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", expected, msg)
+	}
+
+	// Scenario: Duration is stored in BSON as nanoseconds
+	// Given: Documents with nanoseconds and duration string
+	// When: Unmarshaled into duration optional
+	// Then: Both are decoded
+	for _, doc := range []bson.M{{"a": int64(90 * time.Second)}, {"a": "1m30s"}} {
+		msg, _ := bson.Marshal(doc)
+		u := ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal %v, error: %v", doc, err)
+			continue
+		}
+		if u.A != k.A {
+			t.Errorf("[optional] Unexpected unmarshal result, expected: %v, got: %v", k.A, u.A)
+		}
+	}
+
+	// Scenario: Duration is scanned from SQL
+	// Given: Duration stored as text and as nanoseconds
+	// When: Scanned into duration optional
+	// Then: Both are decoded
+	for _, src := range []interface{}{[]byte("1m30s"), int64(90 * time.Second), []byte("90000000000"), "90000000000"} {
+		u := ts{}
+		if err := u.A.Scan(src); err != nil || u.A != k.A {
+			t.Errorf("[optional] Unexpected scan result of %v, got: %v, error: %v", src, u.A, err)
+		}
+	}
+}
+
+func TestDurationSQL(t *testing.T) {
+	db := openFakeDB()
+	defer db.Close()
+
+	// Scenario: Duration is stored and scanned through database/sql
+	// Given: Row written by duration optionals and by text protocol driver
+	d := optional.NewDuration(90*time.Second, true)
+	args := []interface{}{d, optional.Duration{}, []byte("90000000000"), []byte("1m30s"), int64(90 * time.Second)}
+	if _, err := db.Exec("insert", args...); err != nil {
+		t.Fatalf("[optional] Fail to insert %v, error: %v", args, err)
+	}
+
+	// When: Scanned into duration optionals
+	// Then: Every valid column holds the same duration and NULL is invalid
+	var r [5]optional.Duration
+	if err := db.QueryRow("select").Scan(&r[0], &r[1], &r[2], &r[3], &r[4]); err != nil {
+		t.Fatalf("[optional] Fail to scan row, error: %v", err)
+	}
+	if expected := [5]optional.Duration{d, {}, d, d, d}; r != expected {
+		t.Errorf("[optional] Unexpected row, expected: %v, got: %v", expected, r)
+	}
+
+	// Scenario: Text which is neither duration nor integer is rejected
+	// Given: Text of fractional number
+	// When: Scanned into duration optional
+	// Then: Returns error and optional is invalid
+	if err := r[0].Scan([]byte("1.5")); err == nil || r[0].Ok() {
+		t.Errorf("[optional] Expected error from scanning 1.5, got: %v", r[0])
+	}
+}
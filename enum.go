@@ -0,0 +1,105 @@
+package goptional
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+var errEnum = errors.New("value is not allowed")
+
+// EnumSet declares values allowed by Enum through Values of its zero value.
+type EnumSet[T comparable] interface {
+	Values() []T
+}
+
+// Enum is optional form of T which only accepts values declared by S, for example:
+//
+//	type Status string
+//
+//	type Statuses struct{}
+//
+//	func (Statuses) Values() []Status { return []Status{"active", "suspended"} }
+//
+//	type Account struct {
+//		Status goptional.Enum[Status, Statuses] `json:"status"`
+//	}
+//
+// Value which is not allowed makes the optional invalid and flagged as unknown.
+// Unmarshaling JSON reports it only in Strict mode, while SetBSON and Scan always do.
+//
+// Enum wraps Of[T] in an unexported field rather than aliasing it, so that its value is only
+// set through methods which check it against S.
+type Enum[T comparable, S EnumSet[T]] struct {
+	opt[T]
+	unknown bool
+}
+
+// NewEnum creates a new optional. Value which is not allowed makes it invalid.
+func NewEnum[T comparable, S EnumSet[T]](val T, ok bool) Enum[T, S] {
+	var e Enum[T, S]
+	e.Set(val, ok)
+	return e
+}
+
+// Values returns values allowed by the enum, e.g. for schema generation.
+func (e Enum[T, S]) Values() []T {
+	var s S
+	return s.Values()
+}
+
+// IsUnknown returns true if the enum was given a value which is not allowed.
+func (e Enum[T, S]) IsUnknown() bool {
+	return e.unknown
+}
+
+// Set sets value-flag pair of this optional. Value which is not allowed makes it invalid.
+func (e *Enum[T, S]) Set(v T, b bool) {
+	e.opt.Set(v, b)
+	e.check()
+}
+
+// check invalidates the enum holding a value which is not allowed, and reports whether it did.
+func (e *Enum[T, S]) check() bool {
+	e.unknown = e.set && !slices.Contains(e.Values(), e.val)
+	if e.unknown {
+		e.set = false
+	}
+	return e.unknown
+}
+
+// UnmarshalJSON is used to unmarshal JSON into optional value.
+// If unmarshal failed or value is not allowed, optional value is invalid (`Optional.Ok()`
+// would return false) and error is returned only in Strict mode.
+func (e *Enum[T, S]) UnmarshalJSON(b []byte) error {
+	err := e.opt.UnmarshalJSON(b)
+	if e.check() {
+		return unmarshalError(b, &e.val, errEnum)
+	}
+	return err
+}
+
+// SetBSON implements bson.Setter
+func (e *Enum[T, S]) SetBSON(raw bson.Raw) error {
+	return e.setBSON(raw.Kind, raw.Data)
+}
+
+func (e *Enum[T, S]) setBSON(kind byte, data []byte) error {
+	err := e.opt.setBSON(kind, data)
+	if e.check() {
+		return bsonError(kind, reflect.TypeOf(e.val), fmt.Errorf("%w: %v", errEnum, e.val))
+	}
+	return err
+}
+
+// Scan implements sql.Scanner. NULL is scanned as invalid optional.
+func (e *Enum[T, S]) Scan(src interface{}) error {
+	err := e.opt.Scan(src)
+	if e.check() {
+		return fmt.Errorf("Unable to scan %v into %T: %w", src, e.val, errEnum)
+	}
+	return err
+}
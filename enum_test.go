@@ -0,0 +1,117 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+type status string
+
+type statuses struct{}
+
+func (statuses) Values() []status { return []status{"active", "suspended"} }
+
+type levels struct{}
+
+func (levels) Values() []int { return []int{1, 2, 3} }
+
+func TestEnum(t *testing.T) {
+	type ts struct {
+		A optional.Enum[status, statuses] `json:"a" bson:"a"`
+		B optional.Enum[int, levels]      `json:"b" bson:"b"`
+	}
+	defer func(m optional.Mode) { optional.UnmarshalMode = m }(optional.UnmarshalMode)
+
+	// Scenario: Enum accepts only declared values
+	// Given: JSON message with string and integer enum values
+	testCase := []struct {
+		message []byte
+		ok      [2]bool
+		unknown [2]bool
+		err     bool
+	}{
+		// When: Values are declared or null
+		// Then: Optionals are valid unless null
+		{[]byte(`{"a":"active","b":3}`), [2]bool{true, true}, [2]bool{}, false},
+		{[]byte(`{"a":null,"b":null}`), [2]bool{}, [2]bool{}, false},
+		// When: Values are not declared
+		// Then: Optionals are invalid and flagged unknown
+		{[]byte(`{"a":"deleted","b":4}`), [2]bool{}, [2]bool{true, true}, true},
+		// When: Values are of wrong type
+		// Then: Optionals are invalid but not unknown
+		{[]byte(`{"a":1,"b":"1"}`), [2]bool{}, [2]bool{}, true},
+	}
+
+	for _, v := range testCase {
+		// When: Unmarshaled in Lenient mode
+		// Then: No error is reported
+		optional.UnmarshalMode = optional.Lenient
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Unexpected error in lenient mode: %v with message: %s", err, v.message)
+		}
+		if ok := [2]bool{k.A.Ok(), k.B.Ok()}; ok != v.ok {
+			t.Errorf("[optional] Unexpected validity from message: %s, got: %v", v.message, ok)
+		}
+		if unknown := [2]bool{k.A.IsUnknown(), k.B.IsUnknown()}; unknown != v.unknown {
+			t.Errorf("[optional] Unexpected unknown flag from message: %s, got: %v", v.message, unknown)
+		}
+
+		// When: Unmarshaled in Strict mode
+		// Then: Unknown and malformed values are reported
+		optional.UnmarshalMode = optional.Strict
+		err := json.Unmarshal(v.message, &ts{})
+		var uerr *optional.UnmarshalError
+		if errors.As(err, &uerr) != v.err {
+			t.Errorf("[optional] Unexpected error from message: %s, got: %v", v.message, err)
+		}
+	}
+
+	// Scenario: Allowed values are exposed
+	// Given: Enum optional
+	// When: Values is called
+	// Then: Declared values are returned
+	var e optional.Enum[status, statuses]
+	if !reflect.DeepEqual(e.Values(), []status{"active", "suspended"}) {
+		t.Errorf("[optional] Unexpected values: %v", e.Values())
+	}
+
+	// Scenario: Enum is checked when set directly
+	// Given: Undeclared value
+	// When: Enum is created
+	// Then: Optional is invalid
+	if e := optional.NewEnum[status, statuses]("deleted", true); e.Ok() || !e.IsUnknown() {
+		t.Errorf("[optional] Unexpected enum from undeclared value: %v", e)
+	}
+
+	// Scenario: Enum is checked when unmarshaled from BSON or scanned from SQL
+	// Given: Declared and undeclared value
+	for _, v := range []struct {
+		doc bson.M
+		err bool
+	}{
+		{bson.M{"a": "suspended", "b": 1}, false},
+		{bson.M{"a": "deleted"}, true},
+		{bson.M{"b": 7}, true},
+	} {
+		// When: Unmarshaled using mgo
+		// Then: Undeclared value is reported
+		msg, _ := bson.Marshal(v.doc)
+		if err := bson.Unmarshal(msg, &ts{}); (err != nil) != v.err {
+			t.Errorf("[optional] Unexpected unmarshal result of %v, error: %v", v.doc, err)
+		}
+	}
+	k := ts{}
+	if err := k.A.Scan("active"); err != nil || !k.A.Ok() {
+		t.Errorf("[optional] Unexpected scan result: %v, error: %v", k.A, err)
+	}
+	if err := k.B.Scan(int64(9)); err == nil || k.B.Ok() {
+		t.Errorf("[optional] Unexpected scan result: %v, error: %v", k.B, err)
+	}
+}
@@ -0,0 +1,51 @@
+package goptional
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// unmarshalJSON unmarshals b into v, a pointer, with special rules for some types.
+func unmarshalJSON(b []byte, v interface{}) error {
+	switch p := v.(type) {
+	case *time.Time:
+		return unmarshalTime(b, p, RFC3339{})
+	case *time.Duration:
+		return unmarshalDuration(b, p)
+	case **big.Int:
+		return unmarshalBigInt(b, p)
+	case *netip.Addr, *netip.Prefix, **url.URL:
+		return unmarshalText(b, p)
+	}
+	return json.Unmarshal(b, v)
+}
+
+// marshalJSON marshals v into JSON with special rules for some types.
+func marshalJSON(v interface{}) ([]byte, error) {
+	switch d := v.(type) {
+	case json.RawMessage:
+		if d == nil {
+			return []byte("null"), nil
+		}
+		return d, nil
+	case time.Duration:
+		return json.Marshal(d.String())
+	case *big.Int:
+		return marshalBigInt(d, false)
+	case *url.URL:
+		s, _ := text(d)
+		return json.Marshal(s)
+	}
+	// Valid optional holding nil slice or map is marshaled as empty one rather than null.
+	switch rv := reflect.ValueOf(v); {
+	case rv.Kind() == reflect.Slice && rv.IsNil():
+		return json.Marshal(reflect.MakeSlice(rv.Type(), 0, 0).Interface())
+	case rv.Kind() == reflect.Map && rv.IsNil():
+		return json.Marshal(reflect.MakeMap(rv.Type()).Interface())
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,328 @@
+package goptional
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Marshal returns the JSON encoding of v like json.Marshal does, except that struct
+// fields holding an unset optional are omitted. It is meant for Go versions without
+// support for `json:",omitzero"`.
+//
+// Fields of embedded structs are promoted, exported or not, shadowed fields are dropped and
+// the `json:",string"` option is honored as json.Marshal does. Maps with non-string keys are marshaled by json.Marshal as is.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := marshalValue(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalValue(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Kind() == reflect.Interface || !hasMarshaler(v.Type()) {
+			return marshalValue(buf, v.Elem())
+		}
+	case reflect.Struct:
+		if !hasMarshaler(v.Type()) {
+			return marshalStruct(buf, v)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() != reflect.Uint8 && !hasMarshaler(v.Type()) {
+			return marshalArray(buf, v)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Type().Key().Kind() == reflect.String && !hasMarshaler(v.Type()) {
+			return marshalMap(buf, v)
+		}
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+func marshalStruct(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteByte('{')
+	first := true
+	for _, f := range structFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || isUnset(fv) {
+			continue
+		}
+		if hasOption(f.opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		if hasOption(f.opts, "omitzero") && isZeroValue(fv) {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(f.name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if hasOption(f.opts, "string") && isQuotable(f.typ) {
+			if err := marshalQuoted(buf, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := marshalValue(buf, fv); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// field is a struct field written by Marshal, reached from the outer struct through index.
+type field struct {
+	name   string
+	tagged bool
+	opts   string
+	index  []int
+	typ    reflect.Type
+}
+
+// structFields returns the fields of struct type t in the order json.Marshal writes them.
+// Fields of embedded structs are promoted breadth first, and a name used by several fields
+// is resolved by the dominance rules of encoding/json: the shallowest field wins, then the
+// tagged one, and the name is dropped when that still leaves more than one.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	visited := map[reflect.Type]bool{}
+	for next := []field{{typ: t}}; len(next) > 0; {
+		current := next
+		next = nil
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			for i := 0; i < e.typ.NumField(); i++ {
+				f := e.typ.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int(nil), e.index...), i)
+				ft := f.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, field{index: index, typ: ft})
+					continue
+				}
+				if !f.IsExported() {
+					continue
+				}
+				fields = append(fields, field{name: name, tagged: name != "", opts: opts, index: index, typ: f.Type})
+				if name == "" {
+					fields[len(fields)-1].name = f.Name
+				}
+			}
+		}
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		return a.tagged && !b.tagged
+	})
+	dominant := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if j-i == 1 || len(fields[i].index) < len(fields[i+1].index) || fields[i].tagged != fields[i+1].tagged {
+			dominant = append(dominant, fields[i])
+		}
+		i = j
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		a, b := dominant[i].index, dominant[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return dominant
+}
+
+// fieldByIndex returns the field of v at index, or false if it is reached through a nil
+// embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// marshalQuoted marshals v into JSON string holding its JSON encoding, as the
+// `json:",string"` option does. Nil pointer is marshaled as null.
+func marshalQuoted(buf *bytes.Buffer, v reflect.Value) error {
+	var b bytes.Buffer
+	if err := marshalValue(&b, v); err != nil {
+		return err
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		buf.Write(b.Bytes())
+		return nil
+	}
+	q, err := json.Marshal(b.String())
+	if err != nil {
+		return err
+	}
+	buf.Write(q)
+	return nil
+}
+
+// isQuotable reports whether the `json:",string"` option applies to fields of type t,
+// which are scalars or unnamed pointers to them.
+func isQuotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if hasMarshaler(t) {
+		return false
+	}
+	switch k := t.Kind(); {
+	case k == reflect.Bool, k == reflect.String, isNumber(k):
+		return true
+	}
+	return false
+}
+
+func marshalArray(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := marshalValue(buf, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func marshalMap(buf *bytes.Buffer, v reflect.Value) error {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k.String())
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := marshalValue(buf, v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// isUnset returns true if v holds an optional which reports itself as zero.
+func isUnset(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	if _, ok := v.Interface().(Optional); !ok {
+		return false
+	}
+	z, ok := v.Interface().(interface{ IsZero() bool })
+	return ok && z.IsZero()
+}
+
+// isZeroValue follows the rule used by encoding/json for omitzero.
+func isZeroValue(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
+
+func hasMarshaler(t reflect.Type) bool {
+	return t.Implements(marshalerType) || t.Implements(textMarshalerType)
+}
+
+func hasOption(opts, name string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue follows the rule used by encoding/json for omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
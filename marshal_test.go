@@ -0,0 +1,159 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestMarshal(t *testing.T) {
+	type inner struct {
+		C optional.Float64 `json:"c"`
+		D string           `json:"d,omitempty"`
+	}
+	type outer struct {
+		A optional.Int              `json:"a"`
+		B optional.Bool             `json:"b"`
+		N optional.Nullable[string] `json:"n"`
+		I inner                     `json:"i"`
+		L []inner                   `json:"l,omitempty"`
+		P *inner                    `json:"p,omitempty"`
+		S string                    `json:"-"`
+	}
+
+	// Scenario: Unset optionals are omitted at every level
+	// Given: Structs with set and unset optionals
+	testCase := []struct {
+		value    outer
+		expected string
+	}{
+		{outer{}, `{"i":{}}`},
+		{
+			outer{A: optional.NewInt(1, true), B: optional.NewBool(false, true), S: "x"},
+			`{"a":1,"b":false,"i":{}}`,
+		},
+		{
+			outer{N: optional.Null[string](), I: inner{D: "d"}},
+			`{"n":null,"i":{"d":"d"}}`,
+		},
+		{
+			outer{
+				L: []inner{{C: optional.NewFloat64(1.5, true)}, {}},
+				P: &inner{C: optional.NewFloat64(0, false)},
+			},
+			`{"i":{},"l":[{"c":1.5},{}],"p":{}}`,
+		},
+	}
+
+	for _, v := range testCase {
+		// When: Marshaled using Marshal
+		// Then: Returns JSON without unset optionals
+		b, err := optional.Marshal(v.value)
+		if err != nil {
+			t.Errorf("[optional] Error marshal JSON: %v", err)
+			continue
+		}
+		if string(b) != v.expected {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.expected, b)
+		}
+	}
+
+	// Scenario: Unset optionals are omitted by omitzero
+	// Given: Struct tagged with omitzero
+	type zs struct {
+		A optional.Int64  `json:"a,omitzero"`
+		B optional.String `json:"b,omitzero"`
+		C optional.Bool   `json:"c,omitzero"`
+	}
+	// When: Marshaled using JSON.Marshal
+	// Then: Only set optionals are written
+	b, err := json.Marshal(zs{B: optional.NewString("", true)})
+	if err != nil {
+		t.Fatalf("[optional] Error marshal JSON: %v", err)
+	}
+	if string(b) != `{"b":""}` {
+		t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", `{"b":""}`, b)
+	}
+
+	// Scenario: Fields of embedded unexported structs are promoted
+	// Given: Struct embedding unexported struct, pointer and fields with string option
+	type embedded struct {
+		X int          `json:"x"`
+		Y optional.Int `json:"y"`
+	}
+	type es struct {
+		embedded
+		A optional.Int `json:"a"`
+		S int          `json:"s,string"`
+		F *float64     `json:"f,string"`
+		T string       `json:"t,string"`
+		O optional.Int `json:"o,string"`
+	}
+	f := 1.5
+	values := []es{
+		{embedded: embedded{X: 1, Y: optional.NewInt(4, true)}, A: optional.NewInt(2, true), S: 5, F: &f, T: "t", O: optional.NewInt(3, true)},
+		{},
+	}
+
+	for _, v := range values {
+		// When: Marshaled using Marshal and JSON.Marshal
+		// Then: Both write embedded and quoted fields the same way, except unset optionals
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("[optional] Error marshal JSON: %v", err)
+		}
+		b, err := optional.Marshal(v)
+		if err != nil {
+			t.Fatalf("[optional] Error marshal JSON: %v", err)
+		}
+		if v.A.Ok() && string(b) != string(expected) {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", expected, b)
+		}
+		if !v.A.Ok() && string(b) != `{"x":0,"s":"0","f":null,"t":"\"\""}` {
+			t.Errorf("[optional] Unexpected JSON message, got: %s", b)
+		}
+	}
+
+	// Scenario: Shadowed and conflicting fields of embedded structs are dropped
+	// Given: Structs whose fields share names with fields of embedded structs
+	type A struct {
+		X int
+		Y int
+	}
+	type C struct {
+		Y int
+		Z optional.Int
+	}
+	type D struct {
+		Z optional.Int `json:"Z"`
+	}
+	type B struct {
+		A
+		X string
+	}
+	type E struct {
+		A
+		C
+		*D
+	}
+	for _, v := range []interface{}{
+		B{A: A{X: 1, Y: 2}, X: "outer"},
+		E{A: A{X: 1, Y: 2}, C: C{Y: 3, Z: optional.NewInt(4, true)}, D: &D{Z: optional.NewInt(5, true)}},
+		E{A: A{X: 1, Y: 2}, C: C{Y: 3, Z: optional.NewInt(4, true)}},
+	} {
+		// When: Marshaled using Marshal and JSON.Marshal
+		// Then: Both write the dominant fields only
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("[optional] Error marshal JSON: %v", err)
+		}
+		b, err := optional.Marshal(v)
+		if err != nil {
+			t.Fatalf("[optional] Error marshal JSON: %v", err)
+		}
+		if string(b) != string(expected) {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", expected, b)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package goptional
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Mode decides what an optional does with a value it is unable to unmarshal.
+type Mode int
+
+const (
+	// Lenient marks the optional invalid and discards the error.
+	Lenient Mode = iota
+	// Strict marks the optional invalid and returns *UnmarshalError.
+	Strict
+)
+
+// UnmarshalMode is the Mode used by every optional in this package.
+// It defaults to Lenient and is meant to be set once during program initialization.
+var UnmarshalMode = Lenient
+
+// UnmarshalError describes a value which can not be unmarshaled into an optional.
+type UnmarshalError struct {
+	Kind reflect.Kind // Kind of the optional value
+	Raw  []byte       // Raw data being unmarshaled
+	Err  error        // Underlying error, may be nil
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("Unable to unmarshal %s to %v", e.Raw, e.Kind)
+}
+
+// Unwrap returns the underlying error.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// unmarshalError reports failure to unmarshal raw into v (a pointer) according to UnmarshalMode.
+// Returns nil in Lenient mode.
+func unmarshalError(raw []byte, v interface{}, err error) error {
+	if UnmarshalMode != Strict {
+		return nil
+	}
+	return &UnmarshalError{
+		Kind: reflect.TypeOf(v).Elem().Kind(),
+		Raw:  append([]byte(nil), raw...),
+		Err:  err,
+	}
+}
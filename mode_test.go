@@ -0,0 +1,116 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestUnmarshalMode(t *testing.T) {
+	type ts struct {
+		A optional.Int    `json:"a"`
+		B optional.String `json:"b"`
+		C optional.Bool   `json:"c"`
+	}
+	defer func(m optional.Mode) { optional.UnmarshalMode = m }(optional.UnmarshalMode)
+
+	// Scenario: Malformed values in lenient and strict mode
+	// Given: JSON message with value of wrong type
+	testCase := []struct {
+		message []byte
+		kind    reflect.Kind
+		raw     string
+	}{
+		{[]byte(`{"a": "abc"}`), reflect.Int, `"abc"`},
+		{[]byte(`{"a": 1.5}`), reflect.Int, `1.5`},
+		{[]byte(`{"b": 12}`), reflect.String, `12`},
+		{[]byte(`{"c": "yes"}`), reflect.Bool, `"yes"`},
+	}
+
+	for _, v := range testCase {
+		// When: Unmarshaled in Lenient mode
+		// Then: No error is reported and optional is invalid
+		optional.UnmarshalMode = optional.Lenient
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Unexpected error in lenient mode: %v with message: %s", err, v.message)
+		}
+		if k.A.Ok() || k.B.Ok() || k.C.Ok() {
+			t.Errorf("[optional] Unexpected valid optional from message: %s", v.message)
+		}
+
+		// When: Unmarshaled in Strict mode
+		// Then: UnmarshalError naming kind and raw value is reported
+		optional.UnmarshalMode = optional.Strict
+		k = ts{}
+		err := json.Unmarshal(v.message, &k)
+		var uerr *optional.UnmarshalError
+		if !errors.As(err, &uerr) {
+			t.Errorf("[optional] Expected UnmarshalError from message: %s, got: %v", v.message, err)
+			continue
+		}
+		if uerr.Kind != v.kind || string(uerr.Raw) != v.raw {
+			t.Errorf("[optional] Unexpected error, expected: %v and %s, got: %v and %s", v.kind, v.raw, uerr.Kind, uerr.Raw)
+		}
+	}
+
+	// Scenario: Null is never an error
+	// Given: JSON message with null values
+	// When: Unmarshaled in Strict mode
+	// Then: No error is reported
+	optional.UnmarshalMode = optional.Strict
+	k := ts{}
+	if err := json.Unmarshal([]byte(`{"a": null, "b": null, "c": null}`), &k); err != nil {
+		t.Errorf("[optional] Unexpected error for null value: %v", err)
+	}
+}
+
+func TestUnmarshalModeNumeric(t *testing.T) {
+	type ts struct {
+		A optional.Int8    `json:"a"`
+		B optional.Uint32  `json:"b"`
+		C optional.Float32 `json:"c"`
+		D optional.Uint64  `json:"d"`
+	}
+	defer func(m optional.Mode) { optional.UnmarshalMode = m }(optional.UnmarshalMode)
+
+	// Scenario: Numbers beyond width of numeric optionals
+	// Given: JSON message with overflowing or fractional values
+	testCase := []struct {
+		message []byte
+		ok      bool
+	}{
+		{[]byte(`{"a": -128, "b": 4294967295, "c": 1.5, "d": 18446744073709551615}`), true},
+		{[]byte(`{"a": 128}`), false},
+		{[]byte(`{"a": 1.5}`), false},
+		{[]byte(`{"b": -1}`), false},
+		{[]byte(`{"b": 4294967296}`), false},
+		{[]byte(`{"c": 1e39}`), false},
+		{[]byte(`{"d": 18446744073709551616}`), false},
+	}
+
+	for _, v := range testCase {
+		// When: Unmarshaled in Lenient mode
+		// Then: Overflowing optional is invalid
+		optional.UnmarshalMode = optional.Lenient
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Unexpected error in lenient mode: %v with message: %s", err, v.message)
+		}
+		if ok := k.A.Ok() || k.B.Ok() || k.C.Ok() || k.D.Ok(); ok != v.ok {
+			t.Errorf("[optional] Unexpected validity from message: %s, got: %v", v.message, ok)
+		}
+
+		// When: Unmarshaled in Strict mode
+		// Then: Overflow is reported
+		optional.UnmarshalMode = optional.Strict
+		err := json.Unmarshal(v.message, &ts{})
+		var uerr *optional.UnmarshalError
+		if errors.As(err, &uerr) == v.ok {
+			t.Errorf("[optional] Unexpected error from message: %s, got: %v", v.message, err)
+		}
+	}
+}
@@ -0,0 +1,619 @@
+// Package mongocodec provides codecs for optionals to be used with the official MongoDB Go driver.
+// Invalid optionals are encoded as BSON null while BSON null and undefined are decoded
+// as invalid optionals.
+//
+// The codecs live in their own package so that users of gopkg.in/mgo.v2 do not depend on the driver.
+package mongocodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mgobson "gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+// NewRegistry creates a new registry with default codecs of the driver and codecs of optionals.
+// See Register for the optionals covered.
+func NewRegistry() *bsoncodec.Registry {
+	r := bson.NewRegistry()
+	Register(r)
+	return r
+}
+
+// Register registers codecs of optionals into r.
+//
+// Optionals of Go basic types and of the types in goptional, such as goptional.Int or
+// goptional.Decimal, are encoded and decoded by the codecs of the driver registered for
+// their values. Every other optional, including instantiations of the generic wrappers
+// like goptional.Enum or goptional.TimeAs and optionals of user-defined types, is encoded
+// and decoded through its GetBSON and SetBSON in the same shape as gopkg.in/mgo.v2/bson.
+// Use RegisterOf to have values of a user-defined type go through the driver instead.
+func Register(r *bsoncodec.Registry) {
+	r.RegisterInterfaceEncoder(getterType, optionalCodec{})
+	r.RegisterInterfaceDecoder(setterType, optionalCodec{})
+	RegisterOf[int](r)
+	RegisterOf[int8](r)
+	RegisterOf[int16](r)
+	RegisterOf[int32](r)
+	RegisterOf[int64](r)
+	RegisterOf[uint](r)
+	RegisterOf[uint8](r)
+	RegisterOf[uint16](r)
+	RegisterOf[uint32](r)
+	RegisterOf[uint64](r)
+	RegisterOf[string](r)
+	RegisterOf[float32](r)
+	RegisterOf[float64](r)
+	RegisterOf[bool](r)
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.Bool{}), boolCodec{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.Bool{}), boolCodec{})
+	RegisterOf[time.Time](r)
+	RegisterOf[time.Duration](r)
+	RegisterOf[[]byte](r)
+	RegisterOf[goptional.Dec](r)
+	RegisterOf[*big.Int](r)
+	RegisterOf[goptional.UUIDValue](r)
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.UUIDValue{}), uuidCodec{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.UUIDValue{}), uuidCodec{})
+	RegisterOf[netip.Addr](r)
+	r.RegisterTypeEncoder(reflect.TypeOf(netip.Addr{}), textCodec[netip.Addr]{netip.ParseAddr})
+	r.RegisterTypeDecoder(reflect.TypeOf(netip.Addr{}), textCodec[netip.Addr]{netip.ParseAddr})
+	RegisterOf[netip.Prefix](r)
+	r.RegisterTypeEncoder(reflect.TypeOf(netip.Prefix{}), textCodec[netip.Prefix]{netip.ParsePrefix})
+	r.RegisterTypeDecoder(reflect.TypeOf(netip.Prefix{}), textCodec[netip.Prefix]{netip.ParsePrefix})
+	RegisterOf[*url.URL](r)
+	RegisterOf[json.RawMessage](r)
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.RawJSON{}), rawJSONCodec{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.RawJSON{}), rawJSONCodec{})
+	r.RegisterTypeEncoder(reflect.TypeOf((*big.Int)(nil)), bigIntCodec{})
+	r.RegisterTypeDecoder(reflect.TypeOf((*big.Int)(nil)), bigIntCodec{})
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.Dec{}), decCodec{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.Dec{}), decCodec{})
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.Date{}), dateCodec{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.Date{}), dateCodec{})
+}
+
+// RegisterOf registers codecs of goptional.Of[T] and goptional.Nullable[T] into r.
+// Collections are registered by their own type, e.g. RegisterOf[[]goptional.Int] registers
+// codec of goptional.SliceOf[goptional.Int].
+func RegisterOf[T any](r *bsoncodec.Registry) {
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.Of[T]{}), OfCodec[T]{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.Of[T]{}), OfCodec[T]{})
+	r.RegisterTypeEncoder(reflect.TypeOf(goptional.Nullable[T]{}), NullableCodec[T]{})
+	r.RegisterTypeDecoder(reflect.TypeOf(goptional.Nullable[T]{}), NullableCodec[T]{})
+}
+
+// OfCodec is the codec of goptional.Of[T]. Value is encoded and decoded by the codec
+// registered for T, and converted using goptional.Coercion if it does not match T.
+type OfCodec[T any] struct{}
+
+// EncodeValue implements bsoncodec.ValueEncoder
+func (OfCodec[T]) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	o, ok := val.Interface().(goptional.Of[T])
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "OfCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(o)},
+			Received: val,
+		}
+	}
+	v, ok := o.Get()
+	if !ok {
+		return vw.WriteNull()
+	}
+	return encodeValue(ec, vw, v)
+}
+
+// DecodeValue implements bsoncodec.ValueDecoder
+func (OfCodec[T]) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	var o goptional.Of[T]
+	if !val.CanSet() || val.Type() != reflect.TypeOf(o) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "OfCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(o)},
+			Received: val,
+		}
+	}
+	null, err := readNull(vr)
+	if err != nil {
+		return err
+	}
+	if !null {
+		var v T
+		if err := decodeCoerced(dc, vr, &v, goptional.Coercion); err != nil {
+			return err
+		}
+		o.Set(v, true)
+	}
+	val.Set(reflect.ValueOf(o))
+	return nil
+}
+
+// NullableCodec is the codec of goptional.Nullable[T]. BSON null is decoded as null nullable
+// while BSON undefined is decoded as absent one.
+type NullableCodec[T any] struct{}
+
+// EncodeValue implements bsoncodec.ValueEncoder
+func (NullableCodec[T]) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	n, ok := val.Interface().(goptional.Nullable[T])
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "NullableCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(n)},
+			Received: val,
+		}
+	}
+	v, ok := n.Get()
+	if !ok {
+		return vw.WriteNull()
+	}
+	return encodeValue(ec, vw, v)
+}
+
+// DecodeValue implements bsoncodec.ValueDecoder
+func (NullableCodec[T]) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	var n goptional.Nullable[T]
+	if !val.CanSet() || val.Type() != reflect.TypeOf(n) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "NullableCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(n)},
+			Received: val,
+		}
+	}
+	kind := vr.Type()
+	null, err := readNull(vr)
+	if err != nil {
+		return err
+	}
+	switch {
+	case kind == bsontype.Null:
+		n.SetNull()
+	case !null:
+		var v T
+		if err := decodeCoerced(dc, vr, &v, goptional.Coercion); err != nil {
+			return err
+		}
+		n.Set(v, true)
+	}
+	val.Set(reflect.ValueOf(n))
+	return nil
+}
+
+var (
+	getterType = reflect.TypeOf((*getter)(nil)).Elem()
+	setterType = reflect.TypeOf((*setter)(nil)).Elem()
+)
+
+// getter is implemented by every optional.
+type getter interface {
+	goptional.Optional
+	Kind() goptional.Kind
+	GetBSON() (interface{}, error)
+}
+
+// setter is implemented by pointer to every optional.
+type setter interface {
+	goptional.Accessor
+	SetBSON(raw mgobson.Raw) error
+}
+
+// optionalCodec is the codec of optionals without a codec of their own. It encodes and
+// decodes them the way gopkg.in/mgo.v2/bson does, through their GetBSON and SetBSON.
+type optionalCodec struct{}
+
+func (optionalCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || !val.Type().Implements(getterType) {
+		return bsoncodec.ValueEncoderError{
+			Name:     "optionalCodec.EncodeValue",
+			Types:    []reflect.Type{getterType},
+			Received: val,
+		}
+	}
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return vw.WriteNull()
+		}
+		enc, err := ec.LookupEncoder(val.Type().Elem())
+		if err != nil {
+			return err
+		}
+		return enc.EncodeValue(ec, vw, val.Elem())
+	}
+	doc, err := goptional.MarshalBSON(map[string]interface{}{"v": val.Interface()})
+	if err != nil {
+		return err
+	}
+	return encodeValue(ec, vw, bson.Raw(doc).Lookup("v"))
+}
+
+func (optionalCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || !reflect.PtrTo(val.Type()).Implements(setterType) && !val.Type().Implements(setterType) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "optionalCodec.DecodeValue",
+			Types:    []reflect.Type{setterType},
+			Received: val,
+		}
+	}
+	if val.Kind() == reflect.Ptr {
+		if null, err := readNull(vr); null || err != nil {
+			val.Set(reflect.Zero(val.Type()))
+			return err
+		}
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return decodeValue(dc, vr, val.Interface())
+	}
+	var raw bson.RawValue
+	if err := decodeValue(dc, vr, &raw); err != nil {
+		return err
+	}
+	return val.Addr().Interface().(setter).SetBSON(mgobson.Raw{Kind: byte(raw.Type), Data: raw.Value})
+}
+
+// boolCodec is the codec of goptional.Bool which delegates to its embedded Of[bool].
+type boolCodec struct{}
+
+func (boolCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	b, ok := val.Interface().(goptional.Bool)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "boolCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(b)},
+			Received: val,
+		}
+	}
+	return OfCodec[bool]{}.EncodeValue(ec, vw, reflect.ValueOf(b.Of))
+}
+
+func (boolCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != reflect.TypeOf(goptional.Bool{}) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "boolCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(goptional.Bool{})},
+			Received: val,
+		}
+	}
+	return OfCodec[bool]{}.DecodeValue(dc, vr, val.FieldByName("Of"))
+}
+
+// rawJSONCodec is the codec of goptional.RawJSON which delegates to its embedded Nullable[json.RawMessage].
+type rawJSONCodec struct{}
+
+func (rawJSONCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	r, ok := val.Interface().(goptional.RawJSON)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "rawJSONCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(r)},
+			Received: val,
+		}
+	}
+	return NullableCodec[json.RawMessage]{}.EncodeValue(ec, vw, reflect.ValueOf(r.Nullable))
+}
+
+func (rawJSONCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != reflect.TypeOf(goptional.RawJSON{}) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "rawJSONCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(goptional.RawJSON{})},
+			Received: val,
+		}
+	}
+	return NullableCodec[json.RawMessage]{}.DecodeValue(dc, vr, val.FieldByName("Nullable"))
+}
+
+// decCodec is the codec of goptional.Dec which is encoded as decimal128. String and numbers
+// are decoded as well.
+type decCodec struct{}
+
+func (decCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	d, ok := val.Interface().(goptional.Dec)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "decCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(d)},
+			Received: val,
+		}
+	}
+	v, err := primitive.ParseDecimal128(d.String())
+	if err != nil {
+		return err
+	}
+	return vw.WriteDecimal128(v)
+}
+
+func (decCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != reflect.TypeOf(goptional.Dec{}) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "decCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(goptional.Dec{})},
+			Received: val,
+		}
+	}
+	var s string
+	var err error
+	switch vr.Type() {
+	case bsontype.Decimal128:
+		var v primitive.Decimal128
+		v, err = vr.ReadDecimal128()
+		s = v.String()
+	case bsontype.String:
+		s, err = vr.ReadString()
+	case bsontype.Int32:
+		var v int32
+		v, err = vr.ReadInt32()
+		s = strconv.FormatInt(int64(v), 10)
+	case bsontype.Int64:
+		var v int64
+		v, err = vr.ReadInt64()
+		s = strconv.FormatInt(v, 10)
+	case bsontype.Double:
+		var v float64
+		v, err = vr.ReadDouble()
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Errorf("cannot decode %v into a goptional.Dec", vr.Type())
+	}
+	if err != nil {
+		return err
+	}
+	d, err := goptional.ParseDec(s)
+	if err != nil {
+		return err
+	}
+	val.Set(reflect.ValueOf(d))
+	return nil
+}
+
+// bigIntCodec is the codec of *big.Int which is encoded as int64 when it fits, otherwise
+// as decimal128. Integral numbers and string are decoded as well.
+type bigIntCodec struct{}
+
+func (bigIntCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	i, ok := val.Interface().(*big.Int)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "bigIntCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(i)},
+			Received: val,
+		}
+	}
+	if i == nil {
+		return vw.WriteNull()
+	}
+	if i.IsInt64() {
+		return vw.WriteInt64(i.Int64())
+	}
+	v, err := primitive.ParseDecimal128(i.String())
+	if err != nil {
+		return err
+	}
+	return vw.WriteDecimal128(v)
+}
+
+func (bigIntCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != reflect.TypeOf((*big.Int)(nil)) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "bigIntCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf((*big.Int)(nil))},
+			Received: val,
+		}
+	}
+	var d goptional.Dec
+	if err := (decCodec{}).DecodeValue(dc, vr, reflect.ValueOf(&d).Elem()); err != nil {
+		return err
+	}
+	r := d.Rat()
+	if !r.IsInt() {
+		return fmt.Errorf("cannot decode %v into a *big.Int", d)
+	}
+	val.Set(reflect.ValueOf(r.Num()))
+	return nil
+}
+
+// uuidCodec is the codec of goptional.UUIDValue which is encoded as binary of subtype 4.
+// Binary of legacy subtype 3 and string are decoded as well.
+type uuidCodec struct{}
+
+func (uuidCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	u, ok := val.Interface().(goptional.UUIDValue)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "uuidCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(u)},
+			Received: val,
+		}
+	}
+	return vw.WriteBinaryWithSubtype(u[:], bsontype.BinaryUUID)
+}
+
+func (uuidCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != reflect.TypeOf(goptional.UUIDValue{}) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "uuidCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(goptional.UUIDValue{})},
+			Received: val,
+		}
+	}
+	var u goptional.UUIDValue
+	switch vr.Type() {
+	case bsontype.Binary:
+		b, subtype, err := vr.ReadBinary()
+		if err != nil {
+			return err
+		}
+		if (subtype != bsontype.BinaryUUID && subtype != bsontype.BinaryUUIDOld) || len(b) != 16 {
+			return fmt.Errorf("cannot decode binary of subtype %v and length %d into a goptional.UUIDValue", subtype, len(b))
+		}
+		copy(u[:], b)
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		if u, err = goptional.ParseUUID(s); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot decode %v into a goptional.UUIDValue", vr.Type())
+	}
+	val.Set(reflect.ValueOf(u))
+	return nil
+}
+
+// textCodec is the codec of T which is encoded as its text form and decoded using parse.
+type textCodec[T fmt.Stringer] struct {
+	parse func(string) (T, error)
+}
+
+func (textCodec[T]) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	v, ok := val.Interface().(T)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "textCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(v)},
+			Received: val,
+		}
+	}
+	return vw.WriteString(v.String())
+}
+
+func (c textCodec[T]) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	var v T
+	if !val.CanSet() || val.Type() != reflect.TypeOf(v) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "textCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(v)},
+			Received: val,
+		}
+	}
+	if vr.Type() != bsontype.String {
+		return fmt.Errorf("cannot decode %v into a %T", vr.Type(), v)
+	}
+	s, err := vr.ReadString()
+	if err != nil {
+		return err
+	}
+	if v, err = c.parse(s); err != nil {
+		return err
+	}
+	val.Set(reflect.ValueOf(v))
+	return nil
+}
+
+// dateCodec is the codec of goptional.Date which encodes and decodes time at midnight UTC of the date.
+type dateCodec struct{}
+
+func (dateCodec) EncodeValue(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	d, ok := val.Interface().(goptional.Date)
+	if !ok {
+		return bsoncodec.ValueEncoderError{
+			Name:     "dateCodec.EncodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(d)},
+			Received: val,
+		}
+	}
+	v, err := d.GetBSON()
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return vw.WriteNull()
+	}
+	return encodeValue(ec, vw, v.(time.Time))
+}
+
+func (dateCodec) DecodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != reflect.TypeOf(goptional.Date{}) {
+		return bsoncodec.ValueDecoderError{
+			Name:     "dateCodec.DecodeValue",
+			Types:    []reflect.Type{reflect.TypeOf(goptional.Date{})},
+			Received: val,
+		}
+	}
+	var o goptional.Of[time.Time]
+	if err := (OfCodec[time.Time]{}).DecodeValue(dc, vr, reflect.ValueOf(&o).Elem()); err != nil {
+		return err
+	}
+	v, ok := o.Get()
+	val.Set(reflect.ValueOf(goptional.NewDate(v.UTC().Truncate(24*time.Hour), ok)))
+	return nil
+}
+
+// readNull consumes BSON null or undefined from vr and reports whether it did so.
+func readNull(vr bsonrw.ValueReader) (bool, error) {
+	switch vr.Type() {
+	case bsontype.Null:
+		return true, vr.ReadNull()
+	case bsontype.Undefined:
+		return true, vr.ReadUndefined()
+	}
+	return false, nil
+}
+
+func encodeValue[T any](ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, v T) error {
+	rv := reflect.ValueOf(&v).Elem()
+	enc, err := ec.LookupEncoder(rv.Type())
+	if err != nil {
+		return err
+	}
+	return enc.EncodeValue(ec, vw, rv)
+}
+
+// decodeValue decodes value from vr into v, a pointer.
+func decodeValue(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	dec, err := dc.LookupDecoder(rv.Type())
+	if err != nil {
+		return err
+	}
+	return dec.DecodeValue(dc, vr, rv)
+}
+
+// decodeCoerced decodes value from vr into v, a pointer, converting it using c if it does
+// not match v. Unlike the driver, BSON numbers and booleans are left to c when decoded into
+// each other.
+func decodeCoerced(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, v interface{}, c goptional.Coercer) error {
+	var raw bson.RawValue
+	if err := decodeValue(dc, vr, &raw); err != nil {
+		return err
+	}
+	var err error
+	if t := reflect.TypeOf(v).Elem(); crossesBool(raw.Type, t.Kind()) {
+		err = fmt.Errorf("cannot decode %v into a %v", raw.Type, t)
+	} else if err = raw.UnmarshalWithContext(&dc, v); err == nil {
+		return nil
+	}
+	var src interface{}
+	if raw.Unmarshal(&src) != nil || c.Coerce(src, v) != nil {
+		return err
+	}
+	return nil
+}
+
+// crossesBool reports whether BSON value of type t is a number decoded into bool kind k,
+// or a boolean decoded into numeric kind k.
+func crossesBool(t bsontype.Type, k reflect.Kind) bool {
+	switch t {
+	case bsontype.Boolean:
+		return k >= reflect.Int && k <= reflect.Float64
+	case bsontype.Int32, bsontype.Int64, bsontype.Double, bsontype.Decimal128:
+		return k == reflect.Bool
+	}
+	return false
+}
@@ -0,0 +1,410 @@
+package mongocodec_test
+
+import (
+	"bytes"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/rahmatismail/goptional"
+	"github.com/rahmatismail/goptional/mongocodec"
+)
+
+type doc struct {
+	A goptional.Int                `bson:"a"`
+	B goptional.Int64              `bson:"b"`
+	C goptional.String             `bson:"c"`
+	D goptional.Float64            `bson:"d"`
+	E goptional.Bool               `bson:"e"`
+	N goptional.Nullable[string]   `bson:"n"`
+	O goptional.Of[int64]          `bson:"o,omitempty"`
+	L []goptional.Nullable[string] `bson:"l"`
+	T goptional.Time               `bson:"t"`
+	Y goptional.Date               `bson:"y"`
+	Z goptional.Bytes              `bson:"z"`
+	M goptional.Decimal            `bson:"m"`
+	I goptional.BigInt             `bson:"i"`
+	U goptional.UUID               `bson:"u"`
+	P goptional.Prefix             `bson:"p"`
+	W goptional.URL                `bson:"w"`
+}
+
+func dec(s string) goptional.Dec {
+	d, _ := goptional.ParseDec(s)
+	return d
+}
+
+func marshal(t *testing.T, v interface{}) []byte {
+	buf := new(bytes.Buffer)
+	vw, err := bsonrw.NewBSONValueWriter(buf)
+	if err != nil {
+		t.Fatalf("[optional] Fail to create writer: %v", err)
+	}
+	enc, err := bson.NewEncoder(vw)
+	if err != nil {
+		t.Fatalf("[optional] Fail to create encoder: %v", err)
+	}
+	if err := enc.SetRegistry(mongocodec.NewRegistry()); err != nil {
+		t.Fatalf("[optional] Fail to set registry: %v", err)
+	}
+	if err := enc.Encode(v); err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", v, err)
+	}
+	return buf.Bytes()
+}
+
+func unmarshal(t *testing.T, msg []byte, v interface{}) error {
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(msg))
+	if err != nil {
+		t.Fatalf("[optional] Fail to create decoder: %v", err)
+	}
+	if err := dec.SetRegistry(mongocodec.NewRegistry()); err != nil {
+		t.Fatalf("[optional] Fail to set registry: %v", err)
+	}
+	return dec.Decode(v)
+}
+
+func TestCodec(t *testing.T) {
+	// Scenario: Marshal optional to BSON then unmarshal again should retain data
+	// Given: Struct with valid, invalid and null optionals
+	testCase := []doc{
+		{N: goptional.Null[string](), L: []goptional.Nullable[string]{}},
+		{
+			A: goptional.NewInt(1, true), B: goptional.NewInt64(2, true), C: goptional.NewString("c", true),
+			D: goptional.NewFloat64(4.5, true), E: goptional.NewBool(false, true), N: goptional.Null[string](),
+			O: goptional.New(int64(0), true),
+			L: []goptional.Nullable[string]{goptional.NewNullable("x", true), goptional.Null[string]()},
+			T: goptional.NewTime(time.UnixMilli(1500000000123).UTC(), true),
+			Y: goptional.NewDate(time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), true),
+			Z: goptional.NewBytes([]byte{}, true),
+			M: goptional.NewDecimal(dec("-1234.50"), true),
+			I: goptional.NewBigInt(dec("123456789012345678901234567890").Coef(), true),
+			U: goptional.NewUUID(goptional.UUIDValue{0x6b, 0xa7, 15: 0xc8}, true),
+			P: goptional.NewPrefix(netip.MustParsePrefix("2001:db8::/32"), true),
+			W: goptional.NewURL(&url.URL{Scheme: "https", Host: "example.com", Path: "/hook"}, true),
+		},
+	}
+
+	for _, v := range testCase {
+		// When: Marshaled and unmarshaled with the registry
+		// Then: Result matches original data
+		msg := marshal(t, v)
+		var u doc
+		if err := unmarshal(t, msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %v, error: %v", bson.Raw(msg), err)
+			continue
+		}
+		if !reflect.DeepEqual(v, u) {
+			t.Errorf("[optional] Marshal-unmarshal BSON mismatch, got: %v, expected: %v", u, v)
+		}
+	}
+
+	// Scenario: Invalid optionals are encoded as null
+	// Given: Empty struct
+	// When: Marshaled with the registry
+	// Then: Fields are null and omitempty drops invalid optional
+	raw := bson.Raw(marshal(t, doc{}))
+	for _, k := range []string{"a", "b", "c", "d", "e", "n"} {
+		if v := raw.Lookup(k); v.Type != bson.TypeNull {
+			t.Errorf("[optional] Unexpected BSON type of %s, expected: null, got: %v", k, v.Type)
+		}
+	}
+	if _, err := raw.LookupErr("o"); err == nil {
+		t.Errorf("[optional] Invalid optional is not omitted")
+	}
+
+	// Scenario: Null and undefined are decoded as invalid
+	// Given: Document with null and undefined values
+	msg := marshal(t, bson.D{
+		{Key: "a", Value: nil}, {Key: "e", Value: primitive.Undefined{}},
+		{Key: "n", Value: primitive.Undefined{}}, {Key: "o", Value: int32(5)},
+	})
+	// When: Unmarshaled with the registry
+	// Then: Optionals are invalid and undefined nullable is absent
+	u := doc{A: goptional.NewInt(1, true), E: goptional.NewBool(true, true)}
+	if err := unmarshal(t, msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message, error: %v", err)
+	}
+	if u.A.Ok() || u.E.Ok() || !u.N.IsAbsent() {
+		t.Errorf("[optional] Unexpected unmarshal result: %v", u)
+	}
+	if v, ok := u.O.Get(); !ok || v != 5 {
+		t.Errorf("[optional] Unexpected unmarshal result, expected: 5 and true, got: %v and %v", v, ok)
+	}
+
+	// Scenario: Mismatching type is reported
+	// Given: Document with string value
+	// When: Unmarshaled into numeric optional
+	// Then: Returns error
+	msg = marshal(t, bson.D{{Key: "a", Value: "abc"}})
+	if err := unmarshal(t, msg, &u); err == nil {
+		t.Errorf("[optional] Fail to detect unmarshal fail: %v", u)
+	}
+}
+
+type color string
+
+type colors struct{}
+
+func (colors) Values() []color { return []color{"red", "green"} }
+
+func TestEnumCodec(t *testing.T) {
+	type ts struct {
+		C goptional.Enum[color, colors] `bson:"c"`
+	}
+	r := mongocodec.NewRegistry()
+
+	// Scenario: Enum is stored as its value and checked when decoded, without registering it
+	// Given: Documents with declared and undeclared value
+	testCase := []struct {
+		doc      bson.D
+		expected goptional.Enum[color, colors]
+		err      bool
+	}{
+		{bson.D{{Key: "c", Value: "red"}}, goptional.NewEnum[color, colors]("red", true), false},
+		{bson.D{{Key: "c", Value: nil}}, goptional.Enum[color, colors]{}, false},
+		{bson.D{{Key: "c", Value: "blue"}}, goptional.Enum[color, colors]{}, true},
+	}
+
+	for _, v := range testCase {
+		// When: Marshaled and unmarshaled with the registry
+		// Then: Declared value round-trips while undeclared value is reported
+		msg, err := bson.MarshalWithRegistry(r, v.doc)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %v, error: %v", v.doc, err)
+			continue
+		}
+		var u ts
+		if err := bson.UnmarshalWithRegistry(r, msg, &u); (err != nil) != v.err {
+			t.Errorf("[optional] Unexpected unmarshal result of %v, error: %v", v.doc, err)
+			continue
+		}
+		if v.err {
+			continue
+		}
+		if u.C != v.expected {
+			t.Errorf("[optional] Unexpected result of %v, expected: %v, got: %v", v.doc, v.expected, u.C)
+		}
+		if out, err := bson.MarshalWithRegistry(r, u); err != nil || !bytes.Equal(out, msg) {
+			t.Errorf("[optional] Unexpected BSON message, expected: %v, got: %v, error: %v", bson.Raw(msg), bson.Raw(out), err)
+		}
+	}
+}
+
+func TestCoercedCodec(t *testing.T) {
+	type ts struct {
+		I goptional.Int                                     `bson:"i"`
+		B goptional.Bool                                    `bson:"b"`
+		L goptional.Coerced[int, goptional.LenientCoercion] `bson:"l"`
+	}
+	r := mongocodec.NewRegistry()
+	defer func(c goptional.Coercer) { goptional.Coercion = c }(goptional.Coercion)
+
+	// Scenario: Mismatching values are converted depending on Coercion
+	// Given: Document with quoted number and textual bool
+	msg, err := bson.MarshalWithRegistry(r, bson.D{{Key: "i", Value: "42"}, {Key: "b", Value: "yes"}, {Key: "l", Value: "5"}})
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data, error: %v", err)
+	}
+
+	// When: Unmarshaled with strict Coercion
+	// Then: Returns error
+	goptional.Coercion = goptional.StrictCoercion{}
+	if err := bson.UnmarshalWithRegistry(r, msg, &ts{}); err == nil {
+		t.Errorf("[optional] Expected error in strict coercion")
+	}
+	if err := bson.UnmarshalWithRegistry(r, msg, &struct {
+		L goptional.Coerced[int, goptional.LenientCoercion] `bson:"l"`
+	}{}); err != nil {
+		t.Errorf("[optional] Unexpected error of Coerced in strict coercion: %v", err)
+	}
+
+	// When: Number is unmarshaled into bool and bool into number with strict Coercion
+	// Then: Returns error
+	for _, d := range []bson.D{{{Key: "b", Value: 42}}, {{Key: "i", Value: true}}} {
+		m, _ := bson.MarshalWithRegistry(r, d)
+		if err := bson.UnmarshalWithRegistry(r, m, &ts{}); err == nil {
+			t.Errorf("[optional] Expected error of %v in strict coercion", d)
+		}
+	}
+
+	// When: Unmarshaled with lenient Coercion
+	// Then: Values are converted
+	goptional.Coercion = goptional.LenientCoercion{}
+	var u ts
+	if err := bson.UnmarshalWithRegistry(r, msg, &u); err != nil {
+		t.Fatalf("[optional] Unexpected error in lenient coercion: %v", err)
+	}
+	i, _ := u.I.Get()
+	b, _ := u.B.Get()
+	l, _ := u.L.Get()
+	if !u.I.Ok() || !u.B.Ok() || !u.L.Ok() || i != 42 || !b || l != 5 {
+		t.Errorf("[optional] Unexpected result in lenient coercion: %+v", u)
+	}
+}
+
+func TestTimeAsCodec(t *testing.T) {
+	type ts struct {
+		A goptional.TimeAs[goptional.UnixMilli] `bson:"a"`
+		B goptional.TimeAs[goptional.UnixMilli] `bson:"b"`
+	}
+	r := mongocodec.NewRegistry()
+
+	// Scenario: TimeAs is stored as datetime regardless of its format
+	// Given: Valid and invalid TimeAs
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	v := ts{A: goptional.NewTimeAs[goptional.UnixMilli](at, true)}
+
+	// When: Marshaled and unmarshaled with the registry
+	// Then: Returns datetime and null, and the same optionals
+	msg, err := bson.MarshalWithRegistry(r, v)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %v, error: %v", v, err)
+	}
+	expected, _ := bson.Marshal(bson.D{{Key: "a", Value: at}, {Key: "b", Value: nil}})
+	if !bytes.Equal(msg, expected) {
+		t.Errorf("[optional] Unexpected BSON message, expected: %v, got: %v", bson.Raw(expected), bson.Raw(msg))
+	}
+	var u ts
+	if err := bson.UnmarshalWithRegistry(r, msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %v, error: %v", bson.Raw(msg), err)
+	}
+	if a, ok := u.A.Get(); !ok || !a.Equal(at) || u.B.Ok() {
+		t.Errorf("[optional] Unexpected result: %+v", u)
+	}
+}
+
+func TestBigIntAsCodec(t *testing.T) {
+	type ts struct {
+		A goptional.BigIntAs[goptional.Quoted] `bson:"a"`
+	}
+	r := mongocodec.NewRegistry()
+
+	// Scenario: BigIntAs is stored as number regardless of its format
+	// Given: Big integer beyond int64
+	i, _ := new(big.Int).SetString("12345678901234567890", 10)
+	v := ts{A: goptional.NewBigIntAs[goptional.Quoted](i, true)}
+
+	// When: Marshaled and unmarshaled with the registry
+	// Then: Returns decimal128 and the same optional
+	msg, err := bson.MarshalWithRegistry(r, v)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %v, error: %v", v, err)
+	}
+	if k := bson.Raw(msg).Lookup("a").Type; k != bsontype.Decimal128 {
+		t.Errorf("[optional] Unexpected BSON type: %v", k)
+	}
+	var u ts
+	if err := bson.UnmarshalWithRegistry(r, msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %v, error: %v", bson.Raw(msg), err)
+	}
+	if a, ok := u.A.Get(); !ok || a.Cmp(i) != 0 {
+		t.Errorf("[optional] Unexpected result: %v %v", a, ok)
+	}
+}
+
+func TestUUIDAsCodec(t *testing.T) {
+	type ts struct {
+		A goptional.UUIDAs[goptional.UUIDBytes] `bson:"a"`
+	}
+	r := mongocodec.NewRegistry()
+
+	// Scenario: UUIDAs is stored as binary regardless of its storage
+	// Given: Valid UUIDAs
+	v := ts{A: goptional.NewUUIDAs[goptional.UUIDBytes](goptional.UUIDValue{1, 2, 3}, true)}
+
+	// When: Marshaled and unmarshaled with the registry
+	// Then: Returns binary of subtype 4 and the same optional
+	msg, err := bson.MarshalWithRegistry(r, v)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %v, error: %v", v, err)
+	}
+	if subtype, _, ok := bson.Raw(msg).Lookup("a").BinaryOK(); !ok || subtype != 0x04 {
+		t.Errorf("[optional] Unexpected BSON message: %v", bson.Raw(msg))
+	}
+	var u ts
+	if err := bson.UnmarshalWithRegistry(r, msg, &u); err != nil || u != v {
+		t.Errorf("[optional] Unexpected result: %+v, error: %v", u, err)
+	}
+}
+
+type money int64
+
+func TestOptionalCodec(t *testing.T) {
+	type ts struct {
+		A goptional.Of[money]       `bson:"a"`
+		B goptional.Nullable[money] `bson:"b"`
+		C goptional.Of[money]       `bson:"c"`
+		P *goptional.Of[money]      `bson:"p"`
+		Q *goptional.Int            `bson:"q"`
+		R *goptional.Of[money]      `bson:"r"`
+	}
+	r := mongocodec.NewRegistry()
+
+	// Scenario: Optionals without a codec of their own are stored as mgo stores them
+	// Given: Optionals of user-defined type, directly and through pointers
+	p, q := goptional.New(money(3), true), goptional.NewInt(4, true)
+	v := ts{A: goptional.New(money(1), true), B: goptional.Null[money](), P: &p, Q: &q}
+
+	// When: Marshaled and unmarshaled with the registry
+	// Then: Returns the same message as MarshalBSON and the same optionals
+	msg, err := bson.MarshalWithRegistry(r, v)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %v, error: %v", v, err)
+	}
+	expected, err := goptional.MarshalBSON(v)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %v, error: %v", v, err)
+	}
+	if !bytes.Equal(msg, expected) {
+		t.Errorf("[optional] Unexpected BSON message, expected: %v, got: %v", bson.Raw(expected), bson.Raw(msg))
+	}
+	var u ts
+	if err := bson.UnmarshalWithRegistry(r, msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %v, error: %v", bson.Raw(msg), err)
+	}
+	if u.A != v.A || u.B != v.B || u.C.Ok() || u.P == nil || *u.P != p || u.Q == nil || *u.Q != q || u.R != nil {
+		t.Errorf("[optional] Unexpected result: %+v", u)
+	}
+}
+
+func TestBytesAsCodec(t *testing.T) {
+	type ts struct {
+		A goptional.Bytes                  `bson:"a"`
+		B goptional.BytesAs[goptional.Hex] `bson:"b"`
+		C goptional.BytesAs[goptional.Hex] `bson:"c"`
+	}
+	r := mongocodec.NewRegistry()
+
+	// Scenario: BytesAs is stored as binary regardless of its encoding
+	// Given: Valid and invalid BytesAs of Base64 and Hex
+	v := ts{A: goptional.NewBytes([]byte{1, 2}, true), B: goptional.NewBytesAs[goptional.Hex]([]byte{3, 4}, true)}
+
+	// When: Marshaled and unmarshaled with the registry
+	// Then: Returns binary and null, and the same optionals
+	msg, err := bson.MarshalWithRegistry(r, v)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %v, error: %v", v, err)
+	}
+	expected, _ := bson.Marshal(bson.D{{Key: "a", Value: []byte{1, 2}}, {Key: "b", Value: []byte{3, 4}}, {Key: "c", Value: nil}})
+	if !bytes.Equal(msg, expected) {
+		t.Errorf("[optional] Unexpected BSON message, expected: %v, got: %v", bson.Raw(expected), bson.Raw(msg))
+	}
+	var u ts
+	if err := bson.UnmarshalWithRegistry(r, msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %v, error: %v", bson.Raw(msg), err)
+	}
+	a, _ := u.A.Get()
+	b, ok := u.B.Get()
+	if !bytes.Equal(a, []byte{1, 2}) || !ok || !bytes.Equal(b, []byte{3, 4}) || u.C.Ok() {
+		t.Errorf("[optional] Unexpected result: %+v", u)
+	}
+}
@@ -0,0 +1,114 @@
+package goptional
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+)
+
+// Addr is optional form of netip.Addr, an IPv4 or IPv6 address.
+// It is marshaled into JSON, BSON and SQL as its text form, such as "192.0.2.1" or "2001:db8::1".
+type Addr = Of[netip.Addr]
+
+// NewAddr creates a new optional
+func NewAddr(val netip.Addr, ok bool) Addr {
+	return Addr{val, ok}
+}
+
+// Prefix is optional form of netip.Prefix, an IP network in CIDR notation.
+// It is marshaled into JSON, BSON and SQL as its text form, such as "192.0.2.0/24".
+type Prefix = Of[netip.Prefix]
+
+// NewPrefix creates a new optional
+func NewPrefix(val netip.Prefix, ok bool) Prefix {
+	return Prefix{val, ok}
+}
+
+// URL is optional form of *url.URL, an absolute URL having both scheme and host.
+// It is marshaled into JSON, BSON and SQL as its text form.
+type URL = Of[*url.URL]
+
+// NewURL creates a new optional
+func NewURL(val *url.URL, ok bool) URL {
+	return URL{val, ok}
+}
+
+// parseText parses s into v, a pointer to a type stored as text, and reports whether
+// the type is one of them. Empty text is not a valid address nor prefix, and URL has to be absolute.
+func parseText(s string, v interface{}) (ok bool, err error) {
+	switch p := v.(type) {
+	case *netip.Addr:
+		*p, err = netip.ParseAddr(s)
+	case *netip.Prefix:
+		*p, err = netip.ParsePrefix(s)
+	case **url.URL:
+		*p, err = parseURL(s)
+	case *url.URL:
+		var u *url.URL
+		if u, err = parseURL(s); err == nil {
+			*p = *u
+		}
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// parseURL parses s into absolute URL.
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("Unable to use %q as absolute URL", s)
+	}
+	return u, nil
+}
+
+// unmarshalText unmarshals JSON string b into v, a pointer to a type stored as text.
+func unmarshalText(b []byte, v interface{}) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	_, err := parseText(s, v)
+	return err
+}
+
+// scanText converts SQL text src into v, a pointer to a type stored as text.
+func scanText(src interface{}, v interface{}) error {
+	switch s := src.(type) {
+	case string:
+		_, err := parseText(s, v)
+		return err
+	case []byte:
+		_, err := parseText(string(s), v)
+		return err
+	}
+	return fmt.Errorf("Unable to scan %T into %T", src, v)
+}
+
+// text returns text form of v if it is a type stored as text. Invalid address
+// and prefix, as well as nil URL, have no text form and are returned as nil.
+func text(v interface{}) (s interface{}, ok bool) {
+	switch t := v.(type) {
+	case netip.Addr:
+		if !t.IsValid() {
+			return nil, true
+		}
+		return t.String(), true
+	case netip.Prefix:
+		if !t.IsValid() {
+			return nil, true
+		}
+		return t.String(), true
+	case *url.URL:
+		if t == nil {
+			return nil, true
+		}
+		return t.String(), true
+	}
+	return nil, false
+}
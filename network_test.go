@@ -0,0 +1,132 @@
+package optional_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestNetwork(t *testing.T) {
+	type ts struct {
+		A optional.Addr   `json:"a" bson:"a"`
+		P optional.Prefix `json:"p" bson:"p"`
+		U optional.URL    `json:"u" bson:"u"`
+	}
+
+	// Scenario: Unmarshal network values from JSON then marshal again
+	// Given: JSON message with address, prefix and URL
+	testCase := []struct {
+		message  []byte
+		ok       [3]bool
+		expected string
+	}{
+		// When: Values are valid
+		// Then: They are marshaled back in canonical form
+		{
+			[]byte(`{"a":"192.0.2.1","p":"10.0.0.0/8","u":"https://example.com/hook?x=1"}`), [3]bool{true, true, true},
+			`{"a":"192.0.2.1","p":"10.0.0.0/8","u":"https://example.com/hook?x=1"}`,
+		},
+		{
+			[]byte(`{"a":"2001:DB8::0001","p":"2001:db8::/32","u":"ftp://user@example.com:21/file"}`), [3]bool{true, true, true},
+			`{"a":"2001:db8::1","p":"2001:db8::/32","u":"ftp://user@example.com:21/file"}`,
+		},
+		// When: Values are malformed, empty or null
+		// Then: Optionals are invalid
+		{[]byte(`{"a":"256.0.0.1","p":"10.0.0.0","u":"http://[::1"}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+		{[]byte(`{"a":"","p":"","u":1}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+		// When: URL is not absolute
+		// Then: URL optional is invalid
+		{[]byte(`{"u":""}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+		{[]byte(`{"u":"not a url"}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+		{[]byte(`{"u":"/relative"}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+		{[]byte(`{"u":"mailto:user@example.com"}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+		{[]byte(`{"a":null,"p":null,"u":null}`), [3]bool{}, `{"a":null,"p":null,"u":null}`},
+	}
+
+	for _, v := range testCase {
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if ok := [3]bool{k.A.Ok(), k.P.Ok(), k.U.Ok()}; ok != v.ok {
+			t.Errorf("[optional] Unexpected validity from message: %s, got: %v", v.message, ok)
+		}
+		msg, err := json.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		if string(msg) != v.expected {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.expected, msg)
+		}
+		if !v.ok[0] {
+			continue
+		}
+
+		// When: Marshaled using MarshalBSON and mgo, then unmarshaled
+		// Then: Values are stored as strings and result matches original data
+		msg, err = optional.MarshalBSON(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		expected, _ := bson.Marshal(k)
+		if !bytes.Equal(msg, expected) {
+			t.Errorf("[optional] Unexpected BSON message, expected: %x, got: %x", expected, msg)
+		}
+		u, nu := ts{}, ts{}
+		if err := bson.Unmarshal(msg, &u); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		if err := optional.UnmarshalBSON(msg, &nu); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+			continue
+		}
+		for _, r := range []ts{u, nu} {
+			if msg, _ := json.Marshal(r); string(msg) != v.expected {
+				t.Errorf("[optional] Unexpected unmarshal result, expected: %s, got: %s", v.expected, msg)
+			}
+		}
+
+		// When: Converted to driver values and scanned back
+		// Then: Result matches original data
+		u = ts{}
+		a, _ := k.A.Value()
+		p, _ := k.P.Value()
+		w, _ := k.U.Value()
+		if err := u.A.Scan(a); err != nil {
+			t.Errorf("[optional] Fail to scan %v, error: %v", a, err)
+		}
+		if err := u.P.Scan([]byte(p.(string))); err != nil {
+			t.Errorf("[optional] Fail to scan %v, error: %v", p, err)
+		}
+		if err := u.U.Scan(w); err != nil {
+			t.Errorf("[optional] Fail to scan %v, error: %v", w, err)
+		}
+		if msg, _ := json.Marshal(u); string(msg) != v.expected {
+			t.Errorf("[optional] Unexpected scan result, expected: %s, got: %s", v.expected, msg)
+		}
+	}
+
+	// Scenario: URL which is not absolute is rejected from every source
+	// Given: Empty, malformed and relative URL text
+	for _, text := range []string{"", "not a url", "/relative"} {
+		// When: Scanned from SQL and unmarshaled from BSON
+		// Then: Returns error and optional is invalid
+		u := optional.NewURL(&url.URL{}, true)
+		if err := u.Scan(text); err == nil || u.Ok() {
+			t.Errorf("[optional] Expected error from scanning %q, got: %v", text, u)
+		}
+		msg, _ := bson.Marshal(bson.M{"u": text})
+		if err := optional.UnmarshalBSON(msg, &ts{}); err == nil {
+			t.Errorf("[optional] Expected error from unmarshaling %q", text)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package goptional
+
+import (
+	"bytes"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Nullable is optional form of T which tells apart a value that is absent,
+// explicitly null or present. It is meant for PATCH-like payloads where null
+// clears a field and absence leaves it untouched. The zero value is absent.
+//
+// Fields tagged with `bson:",omitempty"` keep the three states through gopkg.in/mgo.v2/bson:
+// absent nullable is omitted while null one is written as BSON null. Without omitempty,
+// absent nullable is written as null as well and reads back as null.
+type Nullable[T any] struct {
+	opt[T]
+	null
+}
+
+// opt and null are embedded in Nullable for the same reason as set is in Of.
+type (
+	opt[T any] = Of[T]
+	null       = bool
+)
+
+// NewNullable creates a new nullable which is present if ok is true and absent otherwise.
+func NewNullable[T any](val T, ok bool) Nullable[T] {
+	return Nullable[T]{opt: opt[T]{val, ok}}
+}
+
+// Null creates a new nullable which is explicitly null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{null: true}
+}
+
+// Ok returns true if nullable value is present.
+func (n Nullable[T]) Ok() bool {
+	return n.opt.set
+}
+
+// Get returns value-flag pair of this nullable.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.opt.Get()
+}
+
+// Set sets value-flag pair of this nullable. Nullable is absent if b is false.
+func (n *Nullable[T]) Set(v T, b bool) {
+	n.opt.Set(v, b)
+	n.null = false
+}
+
+// SetNull marks this nullable as explicitly null.
+func (n *Nullable[T]) SetNull() {
+	var zero T
+	n.opt.Set(zero, false)
+	n.null = true
+}
+
+// IsNull returns true if nullable is explicitly null.
+func (n Nullable[T]) IsNull() bool {
+	return n.null
+}
+
+// IsAbsent returns true if nullable is neither null nor present.
+func (n Nullable[T]) IsAbsent() bool {
+	return !n.null && !n.opt.set
+}
+
+// IsZero returns true if nullable is absent, so that fields tagged with
+// `json:",omitzero"` are omitted when absent but kept as null when null.
+func (n Nullable[T]) IsZero() bool {
+	return n.IsAbsent()
+}
+
+// UnmarshalJSON is used to unmarshal JSON into nullable value.
+// JSON null marks nullable as null, otherwise it behaves as Of.UnmarshalJSON and
+// a value which fails to unmarshal leaves nullable absent.
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	n.null = bytes.Equal(b, []byte("null"))
+	return n.opt.UnmarshalJSON(b)
+}
+
+// MarshalJSON marshals nullable into JSON.
+// Null and absent nullable are marshaled as null.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	return n.opt.MarshalJSON()
+}
+
+// SetBSON implements bson.Setter
+func (n *Nullable[T]) SetBSON(raw bson.Raw) error {
+	return n.setBSON(raw.Kind, raw.Data)
+}
+
+func (n *Nullable[T]) setBSON(kind byte, data []byte) error {
+	n.null = kind == 0x0A
+	return n.opt.setBSON(kind, data)
+}
+
+// GetBSON implements bson.Getter
+func (n Nullable[T]) GetBSON() (interface{}, error) {
+	return n.opt.GetBSON()
+}
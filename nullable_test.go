@@ -0,0 +1,131 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestNullable(t *testing.T) {
+	type patch struct {
+		A optional.Nullable[int]    `json:"a,omitzero" bson:"a"`
+		B optional.Nullable[string] `json:"b,omitzero" bson:"b"`
+	}
+
+	// Scenario: Absent, null and present fields are told apart
+	// Given: PATCH-like JSON message
+	testCase := []struct {
+		message []byte
+		absent  bool
+		null    bool
+		ok      bool
+		val     int
+		output  string
+	}{
+		{[]byte(`{}`), true, false, false, 0, `{}`},
+		{[]byte(`{"a": null}`), false, true, false, 0, `{"a":null}`},
+		{[]byte(`{"a": 0}`), false, false, true, 0, `{"a":0}`},
+		{[]byte(`{"a": 12}`), false, false, true, 12, `{"a":12}`},
+		{[]byte(`{"a": "x"}`), true, false, false, 0, `{}`},
+	}
+
+	for _, v := range testCase {
+		// When: Unmarshaled using JSON.Unmarshal
+		// Then: State of nullable matches the message
+		k := patch{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Error unmarshal JSON: %v with message: %s", err, v.message)
+			continue
+		}
+		if k.A.IsAbsent() != v.absent || k.A.IsNull() != v.null || k.A.Ok() != v.ok {
+			t.Errorf("[optional] Unexpected state from message: %s, got: absent %v, null %v, ok %v",
+				v.message, k.A.IsAbsent(), k.A.IsNull(), k.A.Ok())
+		}
+		if val, _ := k.A.Get(); val != v.val {
+			t.Errorf("[optional] Unexpected value, expected: %v, got: %v", v.val, val)
+		}
+
+		// When: Marshaled using JSON.Marshal with omitzero tag
+		// Then: Absent fields are omitted while null is kept
+		b, err := json.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Error marshal JSON: %v", err)
+			continue
+		}
+		if string(b) != v.output {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.output, b)
+		}
+	}
+
+	// Scenario: Setters move nullable between states
+	// Given: Present nullable
+	n := optional.NewNullable("abc", true)
+	// When: SetNull is called
+	// Then: Nullable is null
+	n.SetNull()
+	if !n.IsNull() || n.Ok() || n.IsAbsent() {
+		t.Errorf("[optional] Nullable is not null after SetNull")
+	}
+	// When: Set is called with false
+	// Then: Nullable is absent
+	n.Set("", false)
+	if n.IsNull() || n.Ok() || !n.IsAbsent() {
+		t.Errorf("[optional] Nullable is not absent after Set")
+	}
+
+	// Scenario: Marshal nullable to BSON then unmarshal again should retain data
+	// Given: Struct with present and null fields
+	tc := patch{A: optional.NewNullable(5, true), B: optional.Null[string]()}
+	msg, err := bson.Marshal(tc)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", tc, err)
+	}
+	// When: Unmarshaled using BSON
+	// Then: Result matches original data
+	var tcu patch
+	if err := bson.Unmarshal(msg, &tcu); err != nil {
+		t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", msg, err)
+	}
+	if !reflect.DeepEqual(tc, tcu) {
+		t.Errorf("[optional] Marshal-unmarshal BSON mismatch, got: %v, expected: %v", tcu, tc)
+	}
+
+	// Scenario: Three states survive mgo with omitempty
+	// Given: Struct with absent, null and present fields tagged with omitempty
+	type omit struct {
+		A optional.Nullable[int] `bson:"a,omitempty"`
+		B optional.Nullable[int] `bson:"b,omitempty"`
+		C optional.Nullable[int] `bson:"c,omitempty"`
+		D optional.Int           `bson:"d,omitempty"`
+		E optional.Int           `bson:"e,omitempty"`
+	}
+	to := omit{B: optional.Null[int](), C: optional.NewNullable(0, true), D: optional.NewInt(0, true)}
+	// When: Marshaled and unmarshaled using mgo
+	// Then: Absent nullable and invalid optional are omitted, the others are kept
+	msg, err = bson.Marshal(to)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", to, err)
+	}
+	var m bson.M
+	if err := bson.Unmarshal(msg, &m); err != nil || len(m) != 3 || m["b"] != nil || m["c"] != 0 || m["d"] != 0 {
+		t.Errorf("[optional] Unexpected BSON document: %v, error: %v", m, err)
+	}
+	var tou omit
+	if err := bson.Unmarshal(msg, &tou); err != nil || !reflect.DeepEqual(to, tou) {
+		t.Errorf("[optional] Marshal-unmarshal BSON mismatch, got: %v, expected: %v, error: %v", tou, to, err)
+	}
+
+	// Scenario: Absent is written as null without omitempty
+	// Given: Struct with absent field not tagged with omitempty
+	// When: Marshaled and unmarshaled using mgo
+	// Then: Field reads back as null
+	msg, _ = bson.Marshal(patch{})
+	tcu = patch{}
+	if err := bson.Unmarshal(msg, &tcu); err != nil || !tcu.A.IsNull() {
+		t.Errorf("[optional] Expected null nullable, got: %v, error: %v", tcu.A, err)
+	}
+}
@@ -2,8 +2,6 @@ package goptional
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
 	"reflect"
 
 	"gopkg.in/mgo.v2/bson"
@@ -11,285 +9,240 @@ import (
 
 // Optional is a interface for type that has additional boolean to indicate whether
 // its value valid or not.
+//
+// Methods reading an optional have value receivers, so that both optionals and pointers
+// to them implement Optional and values in maps or returned by functions can be read
+// directly. Methods modifying an optional have pointer receivers.
+//
+// Accessor extends Optional to read and write optionals without knowing their types.
 type Optional interface {
 	Ok() bool
 }
 
-// Int is optional form of int
-type Int struct {
-	val int
-	set bool
+// Of is generic optional form of T. The zero value is an invalid optional.
+//
+// Of cannot be named Optional since that name belongs to the interface above.
+type Of[T any] struct {
+	val T
+	set
 }
 
-// NewInt creates a new Int optional
-func NewInt(val int, ok bool) Int {
-	return Int{val, ok}
+// set is the validity flag of Of. It is embedded rather than named so that omitempty of
+// gopkg.in/mgo.v2/bson, which skips unexported fields unless they are embedded, omits
+// invalid optionals rather than every optional.
+type set = bool
+
+// New creates a new optional of T
+func New[T any](val T, ok bool) Of[T] {
+	return Of[T]{val, ok}
 }
 
-// Ok returns true if optional valus is valid.
-func (i *Int) Ok() bool {
-	return i.set
+// Ok returns true if optional value is valid.
+func (o Of[T]) Ok() bool {
+	return o.set
 }
 
 // Get returns value-flag pair of this optional.
-func (i *Int) Get() (int, bool) {
-	return i.val, i.set
+func (o Of[T]) Get() (T, bool) {
+	return o.val, o.set
 }
 
 // Set sets value-flag pair of this optional.
-func (i *Int) Set(v int, b bool) {
-	i.val = v
-	i.set = b
-}
-
-// UnmarshalJSON is used to unmarshal JSON into optional value.
-// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false).
-func (i *Int) UnmarshalJSON(b []byte) (err error) {
-	if bytes.Equal(b, []byte("null")) {
-		i.set = false
-		return nil
-	}
-	if err = json.Unmarshal(b, &i.val); err != nil {
-		i.set = false
-		return nil
-	}
-	i.set = true
-	return nil
-}
-
-// MarshalJSON marshals optional into JSON.
-// Returns error if optional is invalid.
-func (i Int) MarshalJSON() ([]byte, error) {
-	if v, ok := i.Get(); ok {
-		return json.Marshal(v)
-	}
-	var v *int64
-	return json.Marshal(v)
-}
-
-// Int64 is optional form of int64.
-type Int64 struct {
-	val int64
-	set bool
-}
-
-// NewInt64 creates a new optional
-func NewInt64(val int64, ok bool) Int64 {
-	return Int64{val, ok}
-}
-
-// Ok returns true if optional value is valid.
-func (i *Int64) Ok() bool {
-	return i.set
+func (o *Of[T]) Set(v T, b bool) {
+	o.val = v
+	o.set = b
 }
 
-// Get returns value-flag pair of this optional.
-func (i *Int64) Get() (int64, bool) {
-	return i.val, i.set
+// IsZero returns true if optional value is invalid, so that fields tagged with
+// `json:",omitzero"` are omitted when invalid.
+func (o Of[T]) IsZero() bool {
+	return !o.set
 }
 
-// Set sets value-flag pair of this optional.
-func (i *Int64) Set(v int64, b bool) {
-	i.val = v
-	i.set = b
+// UnmarshalJSON is used to unmarshal JSON into optional value.
+// Number which overflows T, or has fractional part while T is integer, fails to unmarshal.
+// Value which does not match T is converted using Coercion.
+// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false)
+// and error is returned only in Strict mode.
+func (o *Of[T]) UnmarshalJSON(b []byte) error {
+	return o.unmarshalJSONWith(b, Coercion)
 }
 
-// UnmarshalJSON is used to unmarshal JSON into optional value.
-// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false).
-func (i *Int64) UnmarshalJSON(b []byte) (err error) {
+func (o *Of[T]) unmarshalJSONWith(b []byte, c Coercer) error {
 	if bytes.Equal(b, []byte("null")) {
-		i.set = false
+		o.set = false
 		return nil
 	}
-	if err = json.Unmarshal(b, &i.val); err != nil {
-		i.set = false
-		return nil
+	if err := unmarshalJSON(b, &o.val); err != nil && coerceJSON(c, b, &o.val) != nil {
+		o.set = false
+		return unmarshalError(b, &o.val, err)
 	}
-	i.set = true
+	o.set = true
 	return nil
 }
 
 // MarshalJSON marshals optional into JSON.
-// Returns error if optional is invalid.
-func (i Int64) MarshalJSON() ([]byte, error) {
-	if v, ok := i.Get(); ok {
-		return json.Marshal(v)
+// Invalid optional is marshaled as null.
+func (o Of[T]) MarshalJSON() ([]byte, error) {
+	if v, ok := o.Get(); ok {
+		return marshalJSON(v)
 	}
-	var v *int64
-	return json.Marshal(v)
+	return []byte("null"), nil
 }
 
 // SetBSON implements bson.Setter
-func (i *Int64) SetBSON(raw bson.Raw) error {
-	if raw.Kind == 0x0A {
-		i.set = false
+// Numeric value is widened between BSON int32, int64 and double as long as it fits exactly.
+// Value which does not match T is converted using Coercion.
+func (o *Of[T]) SetBSON(raw bson.Raw) error {
+	return o.setBSON(raw.Kind, raw.Data)
+}
+
+func (o *Of[T]) setBSON(kind byte, data []byte) error {
+	return o.setBSONWith(kind, data, Coercion)
+}
+
+func (o *Of[T]) setBSONWith(kind byte, data []byte, c Coercer) error {
+	if kind == 0x0A || kind == 0x06 {
+		o.set = false
 		return nil
 	}
-	if err := raw.Unmarshal(&i.val); err != nil {
-		i.set = false
-		return fmt.Errorf("Unable to unmarshal data with kind %v to int64", raw.Kind)
+	err := decodeBSON(kind, data, reflect.ValueOf(&o.val).Elem())
+	if err != nil && coerceBSON(c, kind, data, &o.val) != nil {
+		o.set = false
+		return err
 	}
-	i.set = true
+	o.set = true
 	return nil
 }
 
 // GetBSON implements bson.Getter
-func (i Int64) GetBSON() (interface{}, error) {
-	if v, ok := i.Get(); ok {
-		return v, nil
+func (o Of[T]) GetBSON() (interface{}, error) {
+	if v, ok := o.Get(); ok {
+		return getBSON(v)
 	}
 	return nil, nil
 }
 
-// String is optional form of string.
-type String struct {
-	val string
-	set bool
-}
+// Int is optional form of int.
+type Int = Of[int]
 
-// NewString creates a new optional
-func NewString(val string, ok bool) String {
-	return String{val, ok}
+// NewInt creates a new Int optional
+func NewInt(val int, ok bool) Int {
+	return Int{val, ok}
 }
 
-// Ok returns true if optional value is valid.
-func (i *String) Ok() bool {
-	return i.set
-}
+// Int64 is optional form of int64.
+type Int64 = Of[int64]
 
-// Get returns value-flag pair of this optional.
-func (i *String) Get() (string, bool) {
-	return i.val, i.set
+// NewInt64 creates a new optional
+func NewInt64(val int64, ok bool) Int64 {
+	return Int64{val, ok}
 }
 
-// Set sets value-flag pair of this optional.
-func (i *String) Set(v string, b bool) {
-	i.val = v
-	i.set = b
-}
+// Int8 is optional form of int8.
+type Int8 = Of[int8]
 
-// UnmarshalJSON is used to unmarshal JSON into optional value.
-// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false).
-func (i *String) UnmarshalJSON(b []byte) (err error) {
-	if bytes.Equal(b, []byte("null")) {
-		i.set = false
-		return nil
-	}
-	if err = json.Unmarshal(b, &i.val); err != nil {
-		i.set = false
-		return nil
-	}
-	i.set = true
-	return nil
+// NewInt8 creates a new optional
+func NewInt8(val int8, ok bool) Int8 {
+	return Int8{val, ok}
 }
 
-// MarshalJSON marshals optional into JSON.
-// Returns error if optional is invalid.
-func (i String) MarshalJSON() ([]byte, error) {
-	if v, ok := i.Get(); ok {
-		return json.Marshal(v)
-	}
-	var v *string
-	return json.Marshal(v)
+// Int16 is optional form of int16.
+type Int16 = Of[int16]
+
+// NewInt16 creates a new optional
+func NewInt16(val int16, ok bool) Int16 {
+	return Int16{val, ok}
 }
 
-// SetBSON implements bson.Setter
-func (i *String) SetBSON(raw bson.Raw) error {
-	if raw.Kind == 0x0A {
-		i.set = false
-		return nil
-	}
-	if err := raw.Unmarshal(&i.val); err != nil {
-		i.set = false
-		return fmt.Errorf("Unable to unmarshal data with kind %v to string", raw.Kind)
-	}
-	i.set = true
-	return nil
+// Int32 is optional form of int32.
+type Int32 = Of[int32]
+
+// NewInt32 creates a new optional
+func NewInt32(val int32, ok bool) Int32 {
+	return Int32{val, ok}
 }
 
-// GetBSON implements bson.Getter
-func (i String) GetBSON() (interface{}, error) {
-	if v, ok := i.Get(); ok {
-		return v, nil
-	}
-	return nil, nil
+// Uint is optional form of uint.
+type Uint = Of[uint]
+
+// NewUint creates a new optional
+func NewUint(val uint, ok bool) Uint {
+	return Uint{val, ok}
 }
 
-// Float64 is optional form of int64.
-type Float64 struct {
-	val float64
-	set bool
+// Uint8 is optional form of uint8.
+type Uint8 = Of[uint8]
+
+// NewUint8 creates a new optional
+func NewUint8(val uint8, ok bool) Uint8 {
+	return Uint8{val, ok}
 }
 
-// NewFloat64 creates a new optional
-func NewFloat64(val float64, ok bool) Float64 {
-	return Float64{val, ok}
+// Uint16 is optional form of uint16.
+type Uint16 = Of[uint16]
+
+// NewUint16 creates a new optional
+func NewUint16(val uint16, ok bool) Uint16 {
+	return Uint16{val, ok}
 }
 
-// Ok returns true if optional value is valid.
-func (f *Float64) Ok() bool {
-	return f.set
+// Uint32 is optional form of uint32.
+type Uint32 = Of[uint32]
+
+// NewUint32 creates a new optional
+func NewUint32(val uint32, ok bool) Uint32 {
+	return Uint32{val, ok}
 }
 
-// Get returns value-flag pair of this optional.
-func (f *Float64) Get() (float64, bool) {
-	return f.val, f.set
+// Uint64 is optional form of uint64.
+type Uint64 = Of[uint64]
+
+// NewUint64 creates a new optional
+func NewUint64(val uint64, ok bool) Uint64 {
+	return Uint64{val, ok}
 }
 
-// Set sets value-flag pair of this optional.
-func (f *Float64) Set(v float64, b bool) {
-	f.val = v
-	f.set = b
+// String is optional form of string.
+type String = Of[string]
+
+// NewString creates a new optional
+func NewString(val string, ok bool) String {
+	return String{val, ok}
 }
 
-// UnmarshalJSON is used to unmarshal JSON into optional value.
-// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false).
-func (f *Float64) UnmarshalJSON(b []byte) (err error) {
-	if bytes.Equal(b, []byte("null")) {
-		f.set = false
-		return nil
-	}
-	if err = json.Unmarshal(b, &f.val); err != nil {
-		f.set = false
-		return nil
-	}
-	f.set = true
-	return nil
+// Float32 is optional form of float32.
+type Float32 = Of[float32]
+
+// NewFloat32 creates a new optional
+func NewFloat32(val float32, ok bool) Float32 {
+	return Float32{val, ok}
 }
 
-// MarshalJSON marshals optional into JSON.
-// Returns error if optional is invalid.
-func (f Float64) MarshalJSON() ([]byte, error) {
-	if v, ok := f.Get(); ok {
-		return json.Marshal(v)
-	}
-	var v *int64
-	return json.Marshal(v)
+// Float64 is optional form of float64.
+type Float64 = Of[float64]
+
+// NewFloat64 creates a new optional
+func NewFloat64(val float64, ok bool) Float64 {
+	return Float64{val, ok}
 }
 
 // Bool is optional form of bool.
+//
+// Bool wraps Of[bool] rather than aliasing it since its Set accepts any value
+// and its UnmarshalJSON accepts 0 and 1 as well as JSON booleans, besides values
+// converted using Coercion.
 type Bool struct {
-	val bool
-	set bool
+	Of[bool]
 }
 
 // NewBool creates a new optional
 func NewBool(val bool, ok bool) Bool {
-	return Bool{val, ok}
-}
-
-// Ok returns true if optional value is valid.
-func (b *Bool) Ok() bool {
-	return b.set
-}
-
-// Get returns value-flag pair of this optional.
-func (b *Bool) Get() (bool, bool) {
-	return b.val, b.set
+	return Bool{Of[bool]{val, ok}}
 }
 
 // GetInt returns bool as int64
-func (b *Bool) GetInt() (int64, bool) {
+func (b Bool) GetInt() (int64, bool) {
 	if b.val {
 		return 1, b.set
 	}
@@ -309,7 +262,8 @@ func (b *Bool) Set(v interface{}, s bool) {
 }
 
 // UnmarshalJSON is used to unmarshal JSON into optional value.
-// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false).
+// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false)
+// and error is returned only in Strict mode.
 func (b *Bool) UnmarshalJSON(dt []byte) (err error) {
 	if bytes.Equal(dt, []byte("null")) {
 		b.set = false
@@ -320,20 +274,10 @@ func (b *Bool) UnmarshalJSON(dt []byte) (err error) {
 		b.val = true
 	} else if s == "0" || s == "false" {
 		b.val = false
-	} else {
+	} else if coerceJSON(Coercion, dt, &b.val) != nil {
 		b.set = false
-		return nil
+		return unmarshalError(dt, &b.val, nil)
 	}
 	b.set = true
 	return nil
 }
-
-// MarshalJSON marshals optional into JSON.
-// Returns error if optional is invalid.
-func (b Bool) MarshalJSON() ([]byte, error) {
-	if v, ok := b.Get(); ok {
-		return json.Marshal(v)
-	}
-	var v *int64
-	return json.Marshal(v)
-}
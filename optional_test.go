@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"gopkg.in/mgo.v2/bson"
 
@@ -782,6 +783,70 @@ func TestInt(t *testing.T) {
 	}
 }
 
+func TestOf(t *testing.T) {
+	type ts struct {
+		A optional.Of[uint16]  `json:"a" bson:"a"`
+		B optional.Of[float32] `json:"b" bson:"b"`
+		C optional.Float64     `json:"c" bson:"c"`
+		D optional.Bool        `json:"d" bson:"d"`
+	}
+
+	// Scenario: Generic optional behaves the same as named optionals
+	// Given: JSON message with mixed valid, invalid and missing fields
+	testCaseA := []struct {
+		message []byte
+		a, b    bool
+	}{
+		{[]byte(`{}`), false, false},
+		{[]byte(`{"a": null, "b": null}`), false, false},
+		{[]byte(`{"a": 7, "b": 1.5}`), true, true},
+		{[]byte(`{"a": -1, "b": "x"}`), false, false},
+		{[]byte(`{"a": 70000, "b": 2}`), false, true},
+	}
+	for _, v := range testCaseA {
+		// When: Unmarshaled using JSON.Unmarshal
+		// Then: Validity matches the message
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Error unmarshal JSON: %v with message: %s", err, v.message)
+			continue
+		}
+		if k.A.Ok() != v.a || k.B.Ok() != v.b {
+			t.Errorf("[optional] Unexpected validity from message: %s, got: %v and %v", v.message, k.A.Ok(), k.B.Ok())
+		}
+	}
+
+	// Scenario: Marshal optional to BSON then unmarshal again should retain data
+	// Given: Struct with every field filled or empty
+	testCaseB := []ts{
+		{},
+		{
+			optional.New(uint16(9), true), optional.New(float32(0.5), true),
+			optional.NewFloat64(1.25, true), optional.NewBool(true, true),
+		},
+		{
+			optional.New(uint16(0), true), optional.New(float32(0), false),
+			optional.NewFloat64(0, true), optional.NewBool(false, true),
+		},
+	}
+	for _, tc := range testCaseB {
+		// When: Marshaled and unmarshaled using BSON
+		// Then: Result matches original data
+		msg, err := bson.Marshal(tc)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", tc, err)
+			continue
+		}
+		var tcu ts
+		if err := bson.Unmarshal(msg, &tcu); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", msg, err)
+		}
+		if !reflect.DeepEqual(tc, tcu) {
+			t.Errorf("[optional] Marshal-unmarshal BSON mismatch, got: %v, expected: %v", tcu, tc)
+		}
+	}
+}
+
 // SimplifyLiteral removes whitespaces, newlines as well as null value from given msg.
 func SimplifyLiteral(msg []byte) (res []byte) {
 	// Removes null value
@@ -795,3 +860,38 @@ func SimplifyLiteral(msg []byte) (res []byte) {
 	res = commaRe.ReplaceAllLiteral(res, []byte("}"))
 	return
 }
+
+func TestValueReceiver(t *testing.T) {
+	get := func() optional.Int { return optional.NewInt(3, true) }
+
+	// Scenario: Optionals are read without taking their address
+	// Given: Optionals in a map and returned by a function
+	m := map[string]optional.Int{"a": optional.NewInt(1, true)}
+	n := map[string]optional.Nullable[string]{"a": optional.Null[string]()}
+
+	// When: Read directly
+	// Then: Value-flag pair is returned
+	if v, ok := m["a"].Get(); !ok || v != 1 || m["b"].Ok() {
+		t.Errorf("[optional] Unexpected read from map, got: %v %v", v, ok)
+	}
+	if v, ok := get().Get(); !ok || v != 3 {
+		t.Errorf("[optional] Unexpected read from return value, got: %v %v", v, ok)
+	}
+	if !n["a"].IsNull() || n["a"].Ok() || !n["b"].IsAbsent() {
+		t.Errorf("[optional] Unexpected read of nullable from map: %v", n)
+	}
+	if v := optional.OrElse(optional.Map(get(), func(v int) int { return v * 2 }), 0); v != 6 {
+		t.Errorf("[optional] Unexpected result of combinators on values, got: %v", v)
+	}
+
+	// Scenario: Optionals and pointers to them implement Optional
+	// Given: Optionals of every kind
+	// When: Asserted as Optional
+	// Then: Both value and pointer implement it
+	b, s := optional.NewBool(true, true), optional.NewBytes(nil, false)
+	for _, v := range []interface{}{m["a"], &b, b, s, &s, n["a"], optional.NewDate(time.Time{}, true)} {
+		if _, ok := v.(optional.Optional); !ok {
+			t.Errorf("[optional] %T does not implement Optional", v)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package goptional
+
+import (
+	"encoding/json"
+)
+
+// RawJSON is nullable form of json.RawMessage, an arbitrary JSON value kept as bytes.
+// JSON null makes it null and missing key leaves it absent, while any other value is kept
+// exactly as received. Note that encoding/json compacts whitespace when RawJSON is
+// marshaled as part of an enclosing value.
+//
+// RawJSON wraps Nullable[json.RawMessage] rather than aliasing it so that it can be decoded
+// using Decode.
+type RawJSON struct {
+	Nullable[json.RawMessage]
+}
+
+// NewRawJSON creates a new raw JSON which is present if ok is true and absent otherwise.
+func NewRawJSON(val json.RawMessage, ok bool) RawJSON {
+	return RawJSON{NewNullable(val, ok)}
+}
+
+// NullRawJSON creates a new raw JSON which is explicitly null.
+func NullRawJSON() RawJSON {
+	return RawJSON{Null[json.RawMessage]()}
+}
+
+// Decode unmarshals raw JSON into v, the same as json.Unmarshal does.
+// Null and absent raw JSON are decoded as JSON null.
+func (r RawJSON) Decode(v interface{}) error {
+	b, ok := r.Get()
+	if !ok {
+		b = json.RawMessage("null")
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,68 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestRawJSON(t *testing.T) {
+	type ts struct {
+		A optional.RawJSON `json:"a,omitzero"`
+	}
+
+	// Scenario: Raw JSON keeps received value
+	// Given: JSON message with arbitrary, null or missing value
+	testCase := []struct {
+		message  []byte
+		raw      string
+		ok       bool
+		null     bool
+		expected string
+	}{
+		// When: Value is present
+		// Then: Bytes are kept exactly and marshaled back
+		{[]byte(`{"a": {"x": [1, 2.50, "y"]}}`), `{"x": [1, 2.50, "y"]}`, true, false, `{"a":{"x":[1,2.50,"y"]}}`},
+		{[]byte(`{"a": "str"}`), `"str"`, true, false, `{"a":"str"}`},
+		{[]byte(`{"a": 12345678901234567890}`), `12345678901234567890`, true, false, `{"a":12345678901234567890}`},
+		// When: Value is null or missing
+		// Then: Null and absent are told apart
+		{[]byte(`{"a": null}`), ``, false, true, `{"a":null}`},
+		{[]byte(`{}`), ``, false, false, `{}`},
+	}
+
+	for _, v := range testCase {
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		raw, ok := k.A.Get()
+		if string(raw) != v.raw || ok != v.ok || k.A.IsNull() != v.null {
+			t.Errorf("[optional] Unexpected raw JSON from message: %s, got: %s %v %v", v.message, raw, ok, k.A.IsNull())
+		}
+		msg, err := json.Marshal(k)
+		if err != nil {
+			t.Errorf("[optional] Fail to marshal data %#v, error: %v", k, err)
+			continue
+		}
+		if string(msg) != v.expected {
+			t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", v.expected, msg)
+		}
+	}
+
+	// Scenario: Raw JSON is decoded on demand
+	// Given: Present raw JSON and null raw JSON which is not addressable
+	// When: Decoded into a value
+	// Then: Value is decoded the same as json.Unmarshal does
+	r := optional.NewRawJSON(json.RawMessage(`{"x": [1, 2]}`), true)
+	var m map[string][]int
+	if err := r.Decode(&m); err != nil || !reflect.DeepEqual(m, map[string][]int{"x": {1, 2}}) {
+		t.Errorf("[optional] Unexpected decode result: %v, error: %v", m, err)
+	}
+	if err := optional.NullRawJSON().Decode(&m); err != nil || m != nil {
+		t.Errorf("[optional] Unexpected decode result: %v, error: %v", m, err)
+	}
+}
@@ -0,0 +1,93 @@
+package goptional
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"time"
+)
+
+// Scan implements sql.Scanner. NULL is scanned as invalid optional, other values
+// are converted following the rules of sql.Rows.Scan, e.g. []byte is parsed into
+// numeric optionals and int64 is formatted into String. Text is parsed into Time
+// as RFC 3339, into Duration using time.ParseDuration, into BigInt
+// regardless of its size, and into Addr, Prefix and URL from their text form.
+// Value which does not match T is converted using Coercion.
+func (o *Of[T]) Scan(src interface{}) error {
+	return o.scanWith(src, Coercion)
+}
+
+func (o *Of[T]) scanWith(src interface{}, c Coercer) error {
+	if src == nil {
+		var zero T
+		o.val, o.set = zero, false
+		return nil
+	}
+	if err := scanValue(src, &o.val); err != nil && c.Coerce(src, &o.val) != nil {
+		o.set = false
+		return err
+	}
+	o.set = true
+	return nil
+}
+
+// Value implements driver.Valuer. Invalid optional is stored as NULL.
+func (o Of[T]) Value() (driver.Value, error) {
+	if v, ok := o.Get(); ok {
+		return driverValue(v)
+	}
+	return nil, nil
+}
+
+// driverValue converts v into driver.Value with special rules for some types.
+func driverValue(v interface{}) (driver.Value, error) {
+	switch i := v.(type) {
+	case *big.Int:
+		if i == nil {
+			return nil, nil
+		}
+		if i.IsInt64() {
+			return i.Int64(), nil
+		}
+		return i.String(), nil
+	}
+	if s, ok := text(v); ok {
+		return s, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// scanValue converts src, which is not nil, into dst with special rules for some types.
+func scanValue[T any](src interface{}, dst *T) error {
+	switch d := interface{}(dst).(type) {
+	case *time.Time:
+		return scanTime(src, d, RFC3339{}.Layouts())
+	case *time.Duration:
+		if ok, err := scanDuration(src, d); ok {
+			return err
+		}
+	case **big.Int:
+		return scanBigInt(src, d)
+	case *netip.Addr, *netip.Prefix, **url.URL:
+		return scanText(src, d)
+	}
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	*dst = n.V
+	return nil
+}
+
+// Scan implements sql.Scanner. NULL is scanned as null nullable.
+func (n *Nullable[T]) Scan(src interface{}) error {
+	n.null = src == nil
+	return n.opt.Scan(src)
+}
+
+// Value implements driver.Valuer. Null and absent nullable are stored as NULL.
+func (n Nullable[T]) Value() (driver.Value, error) {
+	return n.opt.Value()
+}
@@ -0,0 +1,168 @@
+package optional_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rahmatismail/goptional"
+)
+
+// fakeDriver is an in-memory database/sql driver holding a single table.
+// Every Exec appends its arguments as a row and every Query returns all rows.
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows map[string][][]driver.Value
+}
+
+type fakeConn struct {
+	d   *fakeDriver
+	dsn string
+}
+
+type fakeStmt struct {
+	c *fakeConn
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	i    int
+}
+
+// fakeConnector opens connections to its own fakeDriver, so that every database
+// opened by openFakeDB starts empty.
+type fakeConnector struct {
+	d *fakeDriver
+}
+
+func openFakeDB() *sql.DB {
+	return sql.OpenDB(fakeConnector{&fakeDriver{rows: map[string][][]driver.Value{}}})
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.d }
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	return &fakeConn{d, dsn}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	s.c.d.rows[s.c.dsn] = append(s.c.d.rows[s.c.dsn], args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+	return &fakeRows{rows: s.c.d.rows[s.c.dsn]}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"a", "b", "c", "d", "e"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+type sqlRow struct {
+	A optional.Int
+	B optional.Int64
+	C optional.String
+	D optional.Float64
+	E optional.Bool
+}
+
+func TestSQL(t *testing.T) {
+	db := openFakeDB()
+	defer db.Close()
+
+	// Scenario: Optionals are stored and scanned through database/sql
+	// Given: Rows written by optionals and rows written as raw driver values
+	testCase := []struct {
+		args     []interface{}
+		expected sqlRow
+	}{
+		// When: Optionals are invalid
+		{
+			[]interface{}{optional.Int{}, optional.Int64{}, optional.String{}, optional.Float64{}, optional.Bool{}},
+			sqlRow{},
+		},
+		// When: Optionals are valid
+		{
+			[]interface{}{
+				optional.NewInt(1, true), optional.NewInt64(2, true), optional.NewString("c", true),
+				optional.NewFloat64(4.5, true), optional.NewBool(true, true),
+			},
+			sqlRow{
+				optional.NewInt(1, true), optional.NewInt64(2, true), optional.NewString("c", true),
+				optional.NewFloat64(4.5, true), optional.NewBool(true, true),
+			},
+		},
+		// When: Driver returns text protocol values
+		{
+			[]interface{}{[]byte("7"), []byte("8"), []byte("abc"), []byte("9.25"), []byte("0")},
+			sqlRow{
+				optional.NewInt(7, true), optional.NewInt64(8, true), optional.NewString("abc", true),
+				optional.NewFloat64(9.25, true), optional.NewBool(false, true),
+			},
+		},
+		// When: Driver returns values of other types
+		{
+			[]interface{}{float64(3), int64(4), time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), int64(1), int64(1)},
+			sqlRow{
+				optional.NewInt(3, true), optional.NewInt64(4, true), optional.NewString("2020-01-02T03:04:05Z", true),
+				optional.NewFloat64(1, true), optional.NewBool(true, true),
+			},
+		},
+	}
+	for _, v := range testCase {
+		if _, err := db.Exec("insert", v.args...); err != nil {
+			t.Fatalf("[optional] Fail to insert %v, error: %v", v.args, err)
+		}
+	}
+
+	rows, err := db.Query("select")
+	if err != nil {
+		t.Fatalf("[optional] Fail to query, error: %v", err)
+	}
+	defer rows.Close()
+	for i := 0; rows.Next(); i++ {
+		// Then: Scanned optionals match expected value
+		var r sqlRow
+		if err := rows.Scan(&r.A, &r.B, &r.C, &r.D, &r.E); err != nil {
+			t.Errorf("[optional] Fail to scan row %d, error: %v", i, err)
+			continue
+		}
+		if r != testCase[i].expected {
+			t.Errorf("[optional] Unexpected row %d, expected: %v, got: %v", i, testCase[i].expected, r)
+		}
+	}
+
+	// Scenario: Values which can not be converted are reported
+	// Given: Text which is not a number
+	// When: Scanned into numeric optional
+	// Then: Returns error and optional is invalid
+	v := optional.NewInt(1, true)
+	if err := v.Scan([]byte("abc")); err == nil || v.Ok() {
+		t.Errorf("[optional] Fail to detect scan fail, error: %v, ok: %v", err, v.Ok())
+	}
+}
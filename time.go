@@ -0,0 +1,282 @@
+package goptional
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// TimeFormat tells TimeAs how to read time from JSON and SQL text.
+type TimeFormat interface {
+	// Layouts are tried in order when JSON string or SQL text is parsed.
+	Layouts() []string
+	// UnixUnit is unit of JSON number, e.g. time.Second for Unix seconds.
+	UnixUnit() time.Duration
+}
+
+// RFC3339 reads RFC 3339 text and JSON number as Unix seconds. It is the format of Time.
+type RFC3339 struct{}
+
+// Layouts implements TimeFormat
+func (RFC3339) Layouts() []string {
+	return []string{time.RFC3339Nano}
+}
+
+// UnixUnit implements TimeFormat
+func (RFC3339) UnixUnit() time.Duration {
+	return time.Second
+}
+
+// UnixMilli reads RFC 3339 text and JSON number as Unix milliseconds.
+type UnixMilli struct{}
+
+// Layouts implements TimeFormat
+func (UnixMilli) Layouts() []string {
+	return []string{time.RFC3339Nano}
+}
+
+// UnixUnit implements TimeFormat
+func (UnixMilli) UnixUnit() time.Duration {
+	return time.Millisecond
+}
+
+// Time is optional form of time.Time.
+// It unmarshals JSON string in RFC 3339 and JSON number as Unix seconds,
+// and marshals into RFC 3339 string. Use TimeAs for other formats.
+type Time = Of[time.Time]
+
+// NewTime creates a new optional
+func NewTime(val time.Time, ok bool) Time {
+	return Time{val, ok}
+}
+
+// TimeAs is optional form of time.Time which reads JSON and SQL text using zero value of F
+// rather than the format of Time, for example:
+//
+//	type LogFormat struct{ goptional.UnixMilli }
+//
+//	func (LogFormat) Layouts() []string { return []string{time.DateTime} }
+//
+//	type Log struct {
+//		At goptional.TimeAs[LogFormat] `json:"at"`
+//	}
+//
+// accepts both 1614834367000 and "2021-03-04 05:06:07". It marshals as Time does.
+//
+// TimeAs wraps Of[time.Time] rather than aliasing it since it unmarshals with its own format.
+type TimeAs[F TimeFormat] struct {
+	Of[time.Time]
+}
+
+// NewTimeAs creates a new optional
+func NewTimeAs[F TimeFormat](val time.Time, ok bool) TimeAs[F] {
+	return TimeAs[F]{Of[time.Time]{val, ok}}
+}
+
+// UnmarshalJSON is used to unmarshal JSON into optional value.
+// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false)
+// and error is returned only in Strict mode.
+func (o *TimeAs[F]) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		o.val, o.set = time.Time{}, false
+		return nil
+	}
+	var f F
+	if err := unmarshalTime(b, &o.val, f); err != nil {
+		o.set = false
+		return unmarshalError(b, &o.val, err)
+	}
+	o.set = true
+	return nil
+}
+
+// Scan implements sql.Scanner. NULL is scanned as invalid optional.
+func (o *TimeAs[F]) Scan(src interface{}) error {
+	if src == nil {
+		o.val, o.set = time.Time{}, false
+		return nil
+	}
+	var f F
+	if err := scanTime(src, &o.val, f.Layouts()); err != nil {
+		o.set = false
+		return err
+	}
+	o.set = true
+	return nil
+}
+
+// unmarshalTime unmarshals JSON string or number b into t using format f.
+func unmarshalTime(b []byte, t *time.Time, f TimeFormat) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		return parseTime(s, t, f.Layouts())
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	return parseUnixTime(string(n), t, f.UnixUnit())
+}
+
+// parseTime parses s into t using the first matching layout.
+func parseTime(s string, t *time.Time, layouts []string) error {
+	for _, l := range layouts {
+		if v, err := time.Parse(l, s); err == nil {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("Unable to parse time %q", s)
+}
+
+// Unix time of 0001-01-01T00:00:00Z and 9999-12-31T23:59:59Z, the range of RFC 3339.
+const (
+	minUnixSeconds = -62135596800
+	maxUnixSeconds = 253402300799
+)
+
+// parseUnixTime parses number s as Unix time in unit into t. Time beyond the range
+// of RFC 3339 is rejected.
+func parseUnixTime(s string, t *time.Time, unit time.Duration) error {
+	var sec, nsec int64
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		n := new(big.Int).Mul(big.NewInt(i), big.NewInt(int64(unit)))
+		q, r := n.DivMod(n, big.NewInt(int64(time.Second)), new(big.Int))
+		if !q.IsInt64() {
+			return fmt.Errorf("Unable to use %s as Unix time: %v", s, errOverflow)
+		}
+		sec, nsec = q.Int64(), r.Int64()
+	} else {
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return err
+		}
+		ns := f * float64(unit)
+		fs := math.Floor(ns / float64(time.Second))
+		if !(fs >= minUnixSeconds && fs <= maxUnixSeconds) {
+			return fmt.Errorf("Unable to use %s as Unix time: %v", s, errOverflow)
+		}
+		sec, nsec = int64(fs), int64(ns-fs*float64(time.Second))
+	}
+	if sec < minUnixSeconds || sec > maxUnixSeconds {
+		return fmt.Errorf("Unable to use %s as Unix time: %v", s, errOverflow)
+	}
+	*t = time.Unix(sec, nsec).UTC()
+	return nil
+}
+
+// scanTime converts SQL value src into t, parsing text using layouts.
+func scanTime(src interface{}, t *time.Time, layouts []string) error {
+	switch v := src.(type) {
+	case time.Time:
+		*t = v
+		return nil
+	case string:
+		return parseTime(v, t, layouts)
+	case []byte:
+		return parseTime(string(v), t, layouts)
+	}
+	return fmt.Errorf("Unable to scan %T into time.Time", src)
+}
+
+// Date is optional form of a date without time of day. It marshals into JSON as YYYY-MM-DD
+// string, and into BSON and SQL as time at midnight UTC of the date.
+//
+// Date wraps Of[time.Time] rather than aliasing it since it marshals differently from Time.
+type Date struct {
+	Of[time.Time]
+}
+
+// NewDate creates a new optional
+func NewDate(val time.Time, ok bool) Date {
+	return Date{Of[time.Time]{val, ok}}
+}
+
+// midnight returns time at midnight UTC of the date of t in its location.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// UnmarshalJSON is used to unmarshal JSON into optional value.
+// If unmarshal failed, optional value is invalid (`Optional.Ok()` would return false)
+// and error is returned only in Strict mode.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		d.set = false
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err == nil {
+		err = parseTime(s, &d.val, []string{time.DateOnly})
+	}
+	if err != nil {
+		d.set = false
+		return unmarshalError(b, &d.val, err)
+	}
+	d.set = true
+	return nil
+}
+
+// MarshalJSON marshals optional into JSON.
+// Invalid optional is marshaled as null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if v, ok := d.Get(); ok {
+		return json.Marshal(v.Format(time.DateOnly))
+	}
+	return []byte("null"), nil
+}
+
+// SetBSON implements bson.Setter
+func (d *Date) SetBSON(raw bson.Raw) error {
+	return d.setBSON(raw.Kind, raw.Data)
+}
+
+func (d *Date) setBSON(kind byte, data []byte) error {
+	if err := d.Of.setBSON(kind, data); err != nil {
+		return err
+	}
+	d.val = midnight(d.val.UTC())
+	return nil
+}
+
+// GetBSON implements bson.Getter
+func (d Date) GetBSON() (interface{}, error) {
+	if v, ok := d.Get(); ok {
+		return midnight(v), nil
+	}
+	return nil, nil
+}
+
+// Scan implements sql.Scanner. NULL is scanned as invalid optional.
+func (d *Date) Scan(src interface{}) error {
+	if src == nil {
+		d.val, d.set = time.Time{}, false
+		return nil
+	}
+	if err := scanTime(src, &d.val, []string{time.DateOnly, time.RFC3339Nano}); err != nil {
+		d.set = false
+		return err
+	}
+	d.val, d.set = midnight(d.val), true
+	return nil
+}
+
+// Value implements driver.Valuer. Invalid optional is stored as NULL.
+func (d Date) Value() (driver.Value, error) {
+	if v, ok := d.Get(); ok {
+		return midnight(v), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package optional_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+// dateTime reads Unix seconds and both RFC 3339 and DateTime text.
+type dateTime struct{ optional.RFC3339 }
+
+func (dateTime) Layouts() []string { return []string{time.RFC3339Nano, time.DateTime} }
+
+func TestTime(t *testing.T) {
+	type ts struct {
+		A optional.Time                       `json:"a"`
+		B optional.Date                       `json:"b"`
+		C optional.TimeAs[dateTime]           `json:"c"`
+		M optional.TimeAs[optional.UnixMilli] `json:"m"`
+	}
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	a := func(k ts) (time.Time, bool) { return k.A.Get() }
+	c := func(k ts) (time.Time, bool) { return k.C.Get() }
+	m := func(k ts) (time.Time, bool) { return k.M.Get() }
+
+	// Scenario: Unmarshal time from JSON
+	// Given: JSON message with time in various forms
+	testCase := []struct {
+		message []byte
+		get     func(ts) (time.Time, bool)
+		ok      bool
+		a       time.Time
+	}{
+		// When: Time is in one of the layouts or Unix time of the format
+		// Then: Optional is valid
+		{[]byte(`{"a": "2021-03-04T05:06:07Z"}`), a, true, at},
+		{[]byte(`{"c": "2021-03-04 05:06:07"}`), c, true, at},
+		{[]byte(`{"a": 1614834367}`), a, true, at},
+		{[]byte(`{"m": 1614834367000}`), m, true, at},
+		{[]byte(`{"a": 1614834367.5}`), a, true, at.Add(time.Second / 2)},
+		{[]byte(`{"a": 10000000000}`), a, true, time.Unix(1e10, 0)},
+		// When: Time is malformed, out of range or null
+		// Then: Optional is invalid
+		{[]byte(`{"a": "2021-03-04 05:06:07"}`), a, false, time.Time{}},
+		{[]byte(`{"a": "04/03/2021"}`), a, false, time.Time{}},
+		{[]byte(`{"c": true}`), c, false, time.Time{}},
+		{[]byte(`{"a": 1000000000000000}`), a, false, time.Time{}},
+		{[]byte(`{"a": 1e15}`), a, false, time.Time{}},
+		{[]byte(`{"m": 9223372036854775807}`), m, false, time.Time{}},
+		{[]byte(`{"m": null}`), m, false, time.Time{}},
+	}
+
+	for _, v := range testCase {
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		a, ok := v.get(k)
+		if ok != v.ok || ok && !a.Equal(v.a) {
+			t.Errorf("[optional] Unexpected time from message: %s, got: %v %v", v.message, a, ok)
+		}
+	}
+
+	// Scenario: Time of every format is unmarshaled strictly
+	// Given: Strict mode
+	// When: Unix time is out of range
+	// Then: Returns error
+	defer func(m optional.Mode) { optional.UnmarshalMode = m }(optional.UnmarshalMode)
+	optional.UnmarshalMode = optional.Strict
+	var k ts
+	if err := json.Unmarshal([]byte(`{"c": 1e15}`), &k); err == nil {
+		t.Errorf("[optional] Expected error from out of range Unix time")
+	}
+
+	// Scenario: Date is marshaled without time of day
+	// Given: Date optional
+	// When: Marshaled and unmarshaled again
+	// Then: JSON holds date only and result matches original date
+	k = ts{A: optional.NewTime(at, true), B: optional.NewDate(at, true)}
+	msg, err := json.Marshal(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", k, err)
+	}
+	if expected := `{"a":"2021-03-04T05:06:07Z","b":"2021-03-04","c":null,"m":null}`; string(msg) != expected {
+		t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", expected, msg)
+	}
+	u := ts{}
+	if err := json.Unmarshal(msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %s, error: %v", msg, err)
+	}
+	if d, ok := u.B.Get(); !ok || !d.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("[optional] Unexpected date, got: %v %v", d, ok)
+	}
+
+	// Scenario: Time is stored as BSON datetime
+	// Given: Time and date optionals
+	// When: Marshaled and unmarshaled using mgo
+	// Then: Date is truncated to midnight UTC
+	msg, err = bson.Marshal(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", k, err)
+	}
+	u = ts{}
+	if err := bson.Unmarshal(msg, &u); err != nil {
+		t.Fatalf("[optional] Fail to unmarshal message: %x, error: %v", msg, err)
+	}
+	if a, ok := u.A.Get(); !ok || !a.Equal(at) {
+		t.Errorf("[optional] Unexpected time, got: %v %v", a, ok)
+	}
+	if d, ok := u.B.Get(); !ok || !d.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("[optional] Unexpected date, got: %v %v", d, ok)
+	}
+
+	// Scenario: Time is scanned from SQL text
+	// Given: Timestamp stored as text
+	// When: Scanned into time and date optionals
+	// Then: Text is parsed using layouts of the format
+	u = ts{}
+	if err := u.A.Scan([]byte("2021-03-04 05:06:07")); err == nil || u.A.Ok() {
+		t.Errorf("[optional] Expected error from scanning text which is not RFC 3339")
+	}
+	if err := u.C.Scan([]byte("2021-03-04 05:06:07")); err != nil {
+		t.Errorf("[optional] Fail to scan time, error: %v", err)
+	}
+	if err := u.B.Scan("2021-03-04"); err != nil {
+		t.Errorf("[optional] Fail to scan date, error: %v", err)
+	}
+	if a, ok := u.C.Get(); !ok || !a.Equal(at) {
+		t.Errorf("[optional] Unexpected time, got: %v %v", a, ok)
+	}
+	if d, ok := u.B.Get(); !ok || d.Day() != 4 {
+		t.Errorf("[optional] Unexpected date, got: %v %v", d, ok)
+	}
+}
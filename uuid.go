@@ -0,0 +1,170 @@
+package goptional
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// UUIDValue is a 16-byte universally unique identifier.
+type UUIDValue [16]byte
+
+// ParseUUID parses UUID in canonical form such as "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+// braced form such as "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}", or URN form such as
+// "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8". Hex digits may be of either case.
+func ParseUUID(s string) (UUIDValue, error) {
+	var u UUIDValue
+	c := s
+	switch {
+	case len(c) == 38 && c[0] == '{' && c[37] == '}':
+		c = c[1:37]
+	case len(c) == 45 && strings.EqualFold(c[:9], "urn:uuid:"):
+		c = c[9:]
+	}
+	if len(c) != 36 || c[8] != '-' || c[13] != '-' || c[18] != '-' || c[23] != '-' {
+		return u, fmt.Errorf("Unable to parse UUID %q", s)
+	}
+	h := c[:8] + c[9:13] + c[14:18] + c[19:23] + c[24:]
+	if _, err := hex.Decode(u[:], []byte(h)); err != nil {
+		return UUIDValue{}, fmt.Errorf("Unable to parse UUID %q", s)
+	}
+	return u, nil
+}
+
+// String formats the UUID in lowercase canonical form.
+func (u UUIDValue) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// MarshalText implements encoding.TextMarshaler, so that UUID is marshaled into JSON string.
+func (u UUIDValue) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *UUIDValue) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// Scan implements sql.Scanner. UUID is scanned from 16 bytes or from text.
+func (u *UUIDValue) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.UnmarshalText(v)
+	case string:
+		return u.UnmarshalText([]byte(v))
+	}
+	return fmt.Errorf("Unable to scan %T into UUID", src)
+}
+
+// Value implements driver.Valuer. UUID is stored as canonical text.
+func (u UUIDValue) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// SetBSON implements bson.Setter
+func (u *UUIDValue) SetBSON(raw bson.Raw) error {
+	return u.setBSON(raw.Kind, raw.Data)
+}
+
+// setBSON decodes binary of UUID subtype, either 4 or legacy 3, and string.
+func (u *UUIDValue) setBSON(kind byte, data []byte) error {
+	var err error
+	switch kind {
+	case 0x0A, 0x06:
+		*u = UUIDValue{}
+		return nil
+	case 0x05:
+		var b []byte
+		var subtype byte
+		if b, subtype, err = readBSONBinary(data); err == nil {
+			if (subtype != 0x04 && subtype != 0x03) || len(b) != 16 {
+				err = errKind
+			} else {
+				copy(u[:], b)
+			}
+		}
+	case 0x02:
+		var s string
+		if s, err = readBSONString(data); err == nil {
+			*u, err = ParseUUID(s)
+		}
+	default:
+		err = errKind
+	}
+	if err != nil {
+		return bsonError(kind, reflect.TypeOf(u).Elem(), err)
+	}
+	return nil
+}
+
+// GetBSON implements bson.Getter. UUID is stored as binary of subtype 4.
+func (u UUIDValue) GetBSON() (interface{}, error) {
+	return bsonBinary{kind: 0x04, data: u[:]}, nil
+}
+
+// UUID is optional form of UUIDValue. It is stored in SQL as canonical text.
+type UUID = Of[UUIDValue]
+
+// NewUUID creates a new optional
+func NewUUID(val UUIDValue, ok bool) UUID {
+	return UUID{val, ok}
+}
+
+// UUIDStorage tells UUIDAs how to store UUID in SQL.
+type UUIDStorage interface {
+	// Binary reports whether UUID is stored as 16 bytes rather than canonical text.
+	Binary() bool
+}
+
+// UUIDText stores UUID as canonical text, as UUID does.
+type UUIDText struct{}
+
+// Binary implements UUIDStorage
+func (UUIDText) Binary() bool {
+	return false
+}
+
+// UUIDBytes stores UUID as 16 bytes, e.g. for BINARY(16) columns.
+type UUIDBytes struct{}
+
+// Binary implements UUIDStorage
+func (UUIDBytes) Binary() bool {
+	return true
+}
+
+// UUIDAs is optional form of UUIDValue stored in SQL using zero value of S, e.g.
+// UUIDAs[UUIDBytes] for 16 bytes. Both forms are scanned regardless of S.
+//
+// UUIDAs wraps Of[UUIDValue] rather than aliasing it since it is stored with its own format.
+type UUIDAs[S UUIDStorage] struct {
+	Of[UUIDValue]
+}
+
+// NewUUIDAs creates a new optional
+func NewUUIDAs[S UUIDStorage](val UUIDValue, ok bool) UUIDAs[S] {
+	return UUIDAs[S]{Of[UUIDValue]{val, ok}}
+}
+
+// Value implements driver.Valuer. Invalid optional is stored as NULL.
+func (o UUIDAs[S]) Value() (driver.Value, error) {
+	var s S
+	if v, ok := o.Get(); ok && s.Binary() {
+		return v[:], nil
+	}
+	return o.Of.Value()
+}
@@ -0,0 +1,110 @@
+package optional_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/rahmatismail/goptional"
+)
+
+func TestUUID(t *testing.T) {
+	type ts struct {
+		A optional.UUID `json:"a" bson:"a"`
+	}
+	id := optional.UUIDValue{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+	// Scenario: Unmarshal UUID from JSON
+	// Given: JSON message with UUID in various forms
+	testCase := []struct {
+		message []byte
+		ok      bool
+	}{
+		// When: UUID is in canonical, braced or URN form
+		// Then: Optional is valid
+		{[]byte(`{"a":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`), true},
+		{[]byte(`{"a":"6BA7B810-9DAD-11D1-80B4-00C04FD430C8"}`), true},
+		{[]byte(`{"a":"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}"}`), true},
+		{[]byte(`{"a":"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`), true},
+		// When: UUID is malformed or null
+		// Then: Optional is invalid
+		{[]byte(`{"a":"6ba7b8109dad11d180b400c04fd430c8"}`), false},
+		{[]byte(`{"a":"6ba7b810-9dad-11d1-80b4-00c04fd430cg"}`), false},
+		{[]byte(`{"a":"{6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`), false},
+		{[]byte(`{"a":12}`), false},
+		{[]byte(`{"a":null}`), false},
+	}
+
+	for _, v := range testCase {
+		k := ts{}
+		if err := json.Unmarshal(v.message, &k); err != nil {
+			t.Errorf("[optional] Fail to unmarshal message: %s, error: %v", v.message, err)
+			continue
+		}
+		if u, ok := k.A.Get(); ok != v.ok || ok && u != id {
+			t.Errorf("[optional] Unexpected UUID from message: %s, got: %v %v", v.message, u, ok)
+		}
+	}
+
+	// Scenario: UUID is marshaled in canonical form
+	// Given: Valid UUID optional
+	// When: Marshaled into JSON
+	// Then: JSON holds lowercase canonical UUID
+	k := ts{optional.NewUUID(id, true)}
+	msg, err := json.Marshal(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", k, err)
+	}
+	if expected := `{"a":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`; string(msg) != expected {
+		t.Errorf("[optional] Unexpected JSON message, expected: %s, got: %s", expected, msg)
+	}
+
+	// Scenario: UUID is stored as BSON binary of subtype 4
+	// Given: Valid UUID optional
+	// When: Marshaled using MarshalBSON and mgo, then unmarshaled
+	// Then: Bytes are the same and result matches original data
+	msg, err = optional.MarshalBSON(k)
+	if err != nil {
+		t.Fatalf("[optional] Fail to marshal data %#v, error: %v", k, err)
+	}
+	expected, _ := bson.Marshal(bson.M{"a": bson.Binary{Kind: 0x04, Data: id[:]}})
+	if !bytes.Equal(msg, expected) {
+		t.Errorf("[optional] Unexpected BSON message, expected: %x, got: %x", expected, msg)
+	}
+	if mmsg, _ := bson.Marshal(k); !bytes.Equal(mmsg, expected) {
+		t.Errorf("[optional] Unexpected BSON message from mgo, expected: %x, got: %x", expected, mmsg)
+	}
+	u, nu := ts{}, ts{}
+	if err := bson.Unmarshal(msg, &u); err != nil || u != k {
+		t.Errorf("[optional] Unexpected unmarshal result: %v, error: %v", u, err)
+	}
+	if err := optional.UnmarshalBSON(msg, &nu); err != nil || nu != k {
+		t.Errorf("[optional] Unexpected unmarshal result: %v, error: %v", nu, err)
+	}
+
+	// Scenario: UUID is stored in SQL as text or 16 bytes
+	// Given: Valid UUID optional
+	for _, binary := range []bool{false, true} {
+		// When: Converted to driver value by UUID or UUIDAs[UUIDBytes] and scanned back
+		// Then: Result matches original data
+		var valuer driver.Valuer = k.A
+		if binary {
+			valuer = optional.NewUUIDAs[optional.UUIDBytes](k.A.Get())
+		}
+		dv, err := valuer.Value()
+		if err != nil {
+			t.Errorf("[optional] Fail to convert UUID, error: %v", err)
+			continue
+		}
+		if _, ok := dv.([]byte); ok != binary {
+			t.Errorf("[optional] Unexpected driver value: %v", dv)
+		}
+		u := ts{}
+		if err := u.A.Scan(dv); err != nil || u != k {
+			t.Errorf("[optional] Unexpected scan result: %v, error: %v", u, err)
+		}
+	}
+}